@@ -1,17 +1,41 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"flag"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"net/smtp"
 	"os"
+	"time"
 
 	"github.com/birddigital/store.adrienbird.net/internal/config"
+	"github.com/birddigital/store.adrienbird.net/pkg/admin"
+	"github.com/birddigital/store.adrienbird.net/pkg/alert"
+	"github.com/birddigital/store.adrienbird.net/pkg/cache"
+	"github.com/birddigital/store.adrienbird.net/pkg/cart"
+	"github.com/birddigital/store.adrienbird.net/pkg/catalog"
+	"github.com/birddigital/store.adrienbird.net/pkg/checkout"
 	"github.com/birddigital/store.adrienbird.net/pkg/handlers"
+	"github.com/birddigital/store.adrienbird.net/pkg/idempotency"
+	"github.com/birddigital/store.adrienbird.net/pkg/inventory"
+	"github.com/birddigital/store.adrienbird.net/pkg/search"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/cassette"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+	"github.com/birddigital/store.adrienbird.net/pkg/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	fakeSquarespace := flag.Bool("fake-squarespace", false, "serve Squarespace from an in-process fake seeded with demo data")
+	recordCassette := flag.String("record-squarespace", "", "record Squarespace traffic to a JSON cassette at this path")
+	flag.Parse()
+
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
@@ -28,6 +52,19 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Point the client at an in-process fake for offline development
+	if *fakeSquarespace {
+		fake := sstest.NewServer()
+		defer fake.Close()
+		fake.SeedDemoData()
+
+		cfg.Squarespace.BaseURL = fake.URL
+		if cfg.Squarespace.APIKey == "" && cfg.Squarespace.AccessToken == "" {
+			cfg.Squarespace.AccessToken = fake.Config().AccessToken
+		}
+		log.Printf("Using fake Squarespace API at %s", fake.URL)
+	}
+
 	// Create Gin router
 	router := gin.New()
 
@@ -38,8 +75,8 @@ func main() {
 	// Setup CORS
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "https://adrienbird.net")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -49,28 +86,177 @@ func main() {
 		c.Next()
 	})
 
+	// Create a single Squarespace client shared by all handlers
+	httpClient := newHTTPClient()
+	clientOptions := []squarespace.ClientOption{squarespace.WithHTTPClient(httpClient)}
+	if *recordCassette != "" {
+		clientOptions = append(clientOptions, squarespace.WithTransport(cassette.NewRecorder(*recordCassette, httpClient.Transport)))
+		log.Printf("Recording Squarespace traffic to %s", *recordCassette)
+	}
+	upstream := squarespace.NewClient(&cfg.Squarespace, clientOptions...)
+	var client squarespace.API = upstream
+
+	// Mirror the catalog locally and optionally serve reads from it
+	if cfg.Catalog.MirrorEnabled {
+		mirror, err := catalog.Open(cfg.Catalog.MirrorPath)
+		if err != nil {
+			log.Fatalf("Failed to open catalog mirror: %v", err)
+		}
+		defer mirror.Close()
+
+		syncer := catalog.NewSyncer(mirror, client, cfg.Catalog.SyncInterval, cfg.Catalog.FullSyncInterval)
+		go syncer.Run(context.Background())
+
+		if cfg.Catalog.ServeFromMirror {
+			client = catalog.NewMirrorClient(client, mirror)
+		}
+	}
+
+	// Serve catalog reads from cache
+	var caches []webhook.ProductInvalidator
+	if cfg.Cache.Enabled {
+		cached := squarespace.NewCachedClient(client, cache.New(cache.NewLRU(cfg.Cache.Size)),
+			cache.Policy{
+				TTL:                  cfg.Cache.ProductsTTL,
+				StaleWhileRevalidate: cfg.Cache.StaleWhileRevalidate,
+				StaleIfError:         cfg.Cache.StaleIfError,
+			},
+			cache.Policy{
+				TTL:                  cfg.Cache.ProductTTL,
+				StaleWhileRevalidate: cfg.Cache.StaleWhileRevalidate,
+				StaleIfError:         cfg.Cache.StaleIfError,
+			},
+		)
+		caches = append(caches, cached)
+		client = cached
+	}
+
+	// Keep the product search index fresh in the background
+	searchIndex := search.NewIndex()
+	go searchIndex.Run(context.Background(), client, cfg.Search.RefreshInterval)
+
+	// Server-side carts, identified by signed tokens. Carts and checkout talk
+	// to Squarespace directly so prices and stock are authoritative.
+	cartSecret := []byte(cfg.Cart.Secret)
+	if len(cartSecret) == 0 {
+		log.Println("CART_SECRET not set, using a random secret; cart tokens won't survive restarts")
+		cartSecret = make([]byte, 32)
+		if _, err := rand.Read(cartSecret); err != nil {
+			log.Fatalf("Failed to generate cart secret: %v", err)
+		}
+	}
+	cartStore := cart.NewMemoryStore()
+	go func() {
+		for range time.Tick(time.Hour) {
+			cartStore.Sweep()
+		}
+	}()
+	carts := cart.NewService(upstream, cartStore, cartSecret, cfg.Cart.TTL)
+
+	// Inventory reads and admin adjustments go straight to Squarespace, and
+	// checkout holds stock while the shopper completes the order
+	inventoryService := inventory.NewService(upstream, inventory.NewMemoryAuditLog(cfg.Inventory.AuditLogSize))
+	if len(cfg.Admin.Tokens) == 0 {
+		log.Println("ADMIN_TOKENS not set, admin endpoints will reject every request")
+	}
+	reservations := inventory.NewReservations(upstream, inventoryService, cfg.Inventory.ReservationTTL)
+	go func() {
+		for range time.Tick(time.Minute) {
+			reservations.Sweep()
+		}
+	}()
+	checkouts := checkout.NewService(upstream, carts, reservations)
+
+	// Warn operators when tracked stock runs low
+	if cfg.Alert.Enabled {
+		monitor := alert.NewMonitor(upstream, alert.Thresholds{
+			Default: cfg.Alert.LowStockThreshold,
+			PerSKU:  cfg.Alert.SKUThresholds,
+		}, cfg.Alert.Interval, alertNotifiers(&cfg.Alert, httpClient)...)
+		go monitor.Run(context.Background())
+	}
+
+	// Remember responses to keyed mutating requests so retries are safe
+	idempotencyStore := idempotency.NewMemoryStore()
+	go func() {
+		for range time.Tick(time.Hour) {
+			idempotencyStore.Sweep()
+		}
+	}()
+	idempotent := idempotency.Middleware(idempotencyStore, cfg.Idempotency.TTL)
+
+	// Receive Squarespace webhooks, dropping cached products they affect
+	replayStore := webhook.NewMemoryReplayStore()
+	go func() {
+		for range time.Tick(time.Hour) {
+			replayStore.Sweep()
+		}
+	}()
+	receiver := webhook.NewReceiver(cfg.Webhook.Secrets, replayStore, cfg.Webhook.MaxAge)
+	webhook.InvalidateCaches(receiver, upstream, caches...)
+	if cfg.Webhook.EndpointURL != "" {
+		go reconcileWebhooks(upstream, receiver, &cfg.Webhook)
+	} else if len(cfg.Webhook.Secrets) == 0 {
+		log.Println("SQUARESPACE_WEBHOOK_SECRETS not set, webhook notifications will be rejected")
+	}
+
 	// Initialize handlers
-	productHandler := handlers.NewProductHandler(cfg)
-	orderHandler := handlers.NewOrderHandler(cfg)
-	healthHandler := handlers.NewHealthHandler(cfg)
+	productHandler := handlers.NewProductHandler(cfg, client)
+	orderHandler := handlers.NewOrderHandler(cfg, client)
+	healthHandler := handlers.NewHealthHandler(cfg, client)
+	searchHandler := handlers.NewSearchHandler(searchIndex)
+	cartHandler := handlers.NewCartHandler(carts)
+	checkoutHandler := handlers.NewCheckoutHandler(checkouts)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	webhookHandler := handlers.NewWebhookHandler(receiver)
+	transactionHandler := handlers.NewTransactionHandler(client)
 
 	// Setup routes
 	api := router.Group("/api/v1")
 	{
 		// Product routes
 		api.GET("/products", productHandler.GetProducts)
+		api.GET("/products/search", searchHandler.SearchProducts)
 		api.GET("/products/:id", productHandler.GetProduct)
 		api.GET("/products/:id/variants", productHandler.GetProductVariants)
 
 		// Order routes
 		api.GET("/orders", orderHandler.GetOrders)
 		api.GET("/orders/:id", orderHandler.GetOrder)
-		api.POST("/orders", orderHandler.CreateOrder)
+		api.POST("/orders", idempotent, orderHandler.CreateOrder)
+
+		// Cart routes
+		api.POST("/carts", cartHandler.CreateCart)
+		api.GET("/carts/:token", cartHandler.GetCart)
+		api.POST("/carts/:token/items", cartHandler.AddItem)
+		api.PATCH("/carts/:token/items/:itemId", cartHandler.UpdateItem)
+		api.DELETE("/carts/:token/items/:itemId", cartHandler.RemoveItem)
+		api.DELETE("/carts/:token/items", cartHandler.ClearCart)
+		api.POST("/carts/:token/reservation", checkoutHandler.StartCheckout)
+		api.DELETE("/carts/:token/reservation", checkoutHandler.CancelCheckout)
+
+		// Checkout routes
+		api.POST("/checkout", idempotent, checkoutHandler.Checkout)
+
+		// Inventory routes
+		api.GET("/inventory", inventoryHandler.GetInventoryBatch)
+		api.GET("/inventory/:variantId", inventoryHandler.GetInventory)
+
+		// Webhook routes
+		api.POST("/webhooks/squarespace", webhookHandler.ReceiveSquarespace)
 
 		// Health check
 		api.GET("/health", healthHandler.Health)
 	}
 
+	// Admin routes
+	adminAPI := api.Group("/admin", admin.Middleware(cfg.Admin.Tokens))
+	{
+		adminAPI.POST("/inventory/:variantId/adjustments", idempotent, inventoryHandler.AdjustInventory)
+		adminAPI.GET("/inventory/audit", inventoryHandler.GetAuditLog)
+		adminAPI.GET("/transactions", transactionHandler.GetTransactions)
+	}
+
 	// Add root health endpoint
 	router.GET("/health", healthHandler.Health)
 	router.GET("/", func(c *gin.Context) {
@@ -86,4 +272,62 @@ func main() {
 	if err := router.Run(fmt.Sprintf(":%d", cfg.Server.Port)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// reconcileWebhooks points our Squarespace subscription at the receiver and
+// trusts the secret Squarespace reveals, alongside any configured ones.
+func reconcileWebhooks(api squarespace.API, receiver *webhook.Receiver, cfg *config.WebhookConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	subscription, err := webhook.Reconcile(ctx, api, cfg.EndpointURL, cfg.Topics, cfg.RotateSecret)
+	if err != nil {
+		log.Printf("Failed to reconcile webhook subscriptions: %v", err)
+		return
+	}
+	switch {
+	case subscription.Secret != "":
+		receiver.SetSecrets(append(append([]string(nil), cfg.Secrets...), subscription.Secret))
+	case len(cfg.Secrets) == 0:
+		log.Printf("Webhook subscription %s exists but its secret is unknown; set SQUARESPACE_WEBHOOK_SECRETS or SQUARESPACE_WEBHOOK_ROTATE_SECRET=true", subscription.ID)
+	}
+}
+
+// alertNotifiers always logs alerts and adds email and webhook delivery when
+// they are configured.
+func alertNotifiers(cfg *config.AlertConfig, httpClient *http.Client) []alert.Notifier {
+	notifiers := []alert.Notifier{alert.LogNotifier{}}
+	if cfg.SMTPAddr != "" && len(cfg.SMTPTo) > 0 {
+		var auth smtp.Auth
+		if cfg.SMTPUsername != "" {
+			host, _, _ := net.SplitHostPort(cfg.SMTPAddr)
+			auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, host)
+		}
+		notifiers = append(notifiers, &alert.SMTPNotifier{
+			Addr: cfg.SMTPAddr,
+			Auth: auth,
+			From: cfg.SMTPFrom,
+			To:   cfg.SMTPTo,
+		})
+	}
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, &alert.WebhookNotifier{URL: cfg.WebhookURL, Client: httpClient})
+	}
+	return notifiers
+}
+
+// newHTTPClient builds the HTTP client used for all Squarespace traffic, with
+// a connection pool sized for a single upstream host.
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 50
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSHandshakeTimeout = 5 * time.Second
+	transport.ResponseHeaderTimeout = 20 * time.Second
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+	}
+}
@@ -3,20 +3,31 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Server      ServerConfig      `json:"server"`
 	Squarespace SquarespaceConfig `json:"squarespace"`
+	Cache       CacheConfig       `json:"cache"`
+	Catalog     CatalogConfig     `json:"catalog"`
+	Search      SearchConfig      `json:"search"`
+	Cart        CartConfig        `json:"cart"`
+	Idempotency IdempotencyConfig `json:"idempotency"`
+	Admin       AdminConfig       `json:"admin"`
+	Inventory   InventoryConfig   `json:"inventory"`
+	Alert       AlertConfig       `json:"alert"`
+	Webhook     WebhookConfig     `json:"webhook"`
 }
 
 type ServerConfig struct {
-	Port            int  `json:"port"`
-	Mode            string `json:"mode"`
-	EnableSwagger   bool `json:"enable_swagger"`
-	EnableHealth    bool `json:"enable_health"`
-	EnableMetrics   bool `json:"enable_metrics"`
-	EnableTracing   bool `json:"enable_tracing"`
+	Port          int    `json:"port"`
+	Mode          string `json:"mode"`
+	EnableSwagger bool   `json:"enable_swagger"`
+	EnableHealth  bool   `json:"enable_health"`
+	EnableMetrics bool   `json:"enable_metrics"`
+	EnableTracing bool   `json:"enable_tracing"`
 }
 
 type SquarespaceConfig struct {
@@ -25,17 +36,95 @@ type SquarespaceConfig struct {
 	APIKey      string `json:"api_key"`
 	AccessToken string `json:"access_token"`
 	Environment string `json:"environment"`
+
+	RequestTimeout   time.Duration `json:"request_timeout"`
+	RetryMaxAttempts int           `json:"retry_max_attempts"`
+	RetryBaseDelay   time.Duration `json:"retry_base_delay"`
+	RetryMaxDelay    time.Duration `json:"retry_max_delay"`
+
+	BreakerFailureThreshold int           `json:"breaker_failure_threshold"`
+	BreakerOpenTimeout      time.Duration `json:"breaker_open_timeout"`
+	BreakerHalfOpenRequests int           `json:"breaker_half_open_requests"`
+}
+
+type CacheConfig struct {
+	Enabled              bool          `json:"enabled"`
+	Size                 int           `json:"size"`
+	ProductsTTL          time.Duration `json:"products_ttl"`
+	ProductTTL           time.Duration `json:"product_ttl"`
+	StaleWhileRevalidate time.Duration `json:"stale_while_revalidate"`
+	StaleIfError         time.Duration `json:"stale_if_error"`
+}
+
+type CatalogConfig struct {
+	MirrorEnabled    bool          `json:"mirror_enabled"`
+	ServeFromMirror  bool          `json:"serve_from_mirror"`
+	MirrorPath       string        `json:"mirror_path"`
+	SyncInterval     time.Duration `json:"sync_interval"`
+	FullSyncInterval time.Duration `json:"full_sync_interval"`
+}
+
+type SearchConfig struct {
+	RefreshInterval time.Duration `json:"refresh_interval"`
+}
+
+type CartConfig struct {
+	Secret string        `json:"-"`
+	TTL    time.Duration `json:"ttl"`
+}
+
+type IdempotencyConfig struct {
+	TTL time.Duration `json:"ttl"`
+}
+
+type AdminConfig struct {
+	// Tokens maps operator names to bearer tokens for admin endpoints.
+	Tokens map[string]string `json:"-"`
+}
+
+type InventoryConfig struct {
+	AuditLogSize   int           `json:"audit_log_size"`
+	ReservationTTL time.Duration `json:"reservation_ttl"`
+}
+
+type AlertConfig struct {
+	Enabled           bool           `json:"enabled"`
+	Interval          time.Duration  `json:"interval"`
+	LowStockThreshold int            `json:"low_stock_threshold"`
+	SKUThresholds     map[string]int `json:"sku_thresholds"`
+
+	SMTPAddr     string   `json:"smtp_addr"`
+	SMTPUsername string   `json:"smtp_username"`
+	SMTPPassword string   `json:"-"`
+	SMTPFrom     string   `json:"smtp_from"`
+	SMTPTo       []string `json:"smtp_to"`
+
+	WebhookURL string `json:"webhook_url"`
+}
+
+type WebhookConfig struct {
+	// Secrets are the signing secrets of our Squarespace webhook subscriptions.
+	Secrets []string      `json:"-"`
+	MaxAge  time.Duration `json:"max_age"`
+
+	// EndpointURL is where Squarespace should deliver notifications. When
+	// set, subscriptions for Topics are reconciled at startup.
+	EndpointURL string   `json:"endpoint_url"`
+	Topics      []string `json:"topics"`
+	// RotateSecret rotates an existing subscription's secret during
+	// reconciliation so this instance learns it, invalidating the old one.
+	RotateSecret bool `json:"rotate_secret"`
 }
 
 func Load() (*Config, error) {
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:            getEnvAsInt("PORT", 8080),
-			Mode:            getEnv("GIN_MODE", "debug"),
-			EnableSwagger:   getEnvAsBool("ENABLE_SWAGGER", true),
-			EnableHealth:    getEnvAsBool("ENABLE_HEALTH", true),
-			EnableMetrics:   getEnvAsBool("ENABLE_METRICS", false),
-			EnableTracing:   getEnvAsBool("ENABLE_TRACING", false),
+			Port:          getEnvAsInt("PORT", 8080),
+			Mode:          getEnv("GIN_MODE", "debug"),
+			EnableSwagger: getEnvAsBool("ENABLE_SWAGGER", true),
+			EnableHealth:  getEnvAsBool("ENABLE_HEALTH", true),
+			EnableMetrics: getEnvAsBool("ENABLE_METRICS", false),
+			EnableTracing: getEnvAsBool("ENABLE_TRACING", false),
 		},
 		Squarespace: SquarespaceConfig{
 			BaseURL:     getEnv("SQUARESPACE_BASE_URL", "https://api.squarespace.com"),
@@ -43,6 +132,66 @@ func Load() (*Config, error) {
 			APIKey:      os.Getenv("SQUARESPACE_API_KEY"),
 			AccessToken: os.Getenv("SQUARESPACE_ACCESS_TOKEN"),
 			Environment: getEnv("NODE_ENV", "development"),
+
+			RequestTimeout:   getEnvAsDuration("SQUARESPACE_REQUEST_TIMEOUT", 10*time.Second),
+			RetryMaxAttempts: getEnvAsInt("SQUARESPACE_RETRY_MAX_ATTEMPTS", 3),
+			RetryBaseDelay:   getEnvAsDuration("SQUARESPACE_RETRY_BASE_DELAY", 200*time.Millisecond),
+			RetryMaxDelay:    getEnvAsDuration("SQUARESPACE_RETRY_MAX_DELAY", 5*time.Second),
+
+			BreakerFailureThreshold: getEnvAsInt("SQUARESPACE_BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerOpenTimeout:      getEnvAsDuration("SQUARESPACE_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+			BreakerHalfOpenRequests: getEnvAsInt("SQUARESPACE_BREAKER_HALF_OPEN_REQUESTS", 1),
+		},
+		Cache: CacheConfig{
+			Enabled:              getEnvAsBool("CACHE_ENABLED", true),
+			Size:                 getEnvAsInt("CACHE_SIZE", 1000),
+			ProductsTTL:          getEnvAsDuration("CACHE_PRODUCTS_TTL", time.Minute),
+			ProductTTL:           getEnvAsDuration("CACHE_PRODUCT_TTL", 5*time.Minute),
+			StaleWhileRevalidate: getEnvAsDuration("CACHE_STALE_WHILE_REVALIDATE", 5*time.Minute),
+			StaleIfError:         getEnvAsDuration("CACHE_STALE_IF_ERROR", 24*time.Hour),
+		},
+		Catalog: CatalogConfig{
+			MirrorEnabled:    getEnvAsBool("CATALOG_MIRROR_ENABLED", false),
+			ServeFromMirror:  getEnvAsBool("CATALOG_SERVE_FROM_MIRROR", false),
+			MirrorPath:       getEnv("CATALOG_MIRROR_PATH", "catalog.db"),
+			SyncInterval:     getEnvAsDuration("CATALOG_SYNC_INTERVAL", 5*time.Minute),
+			FullSyncInterval: getEnvAsDuration("CATALOG_FULL_SYNC_INTERVAL", time.Hour),
+		},
+		Search: SearchConfig{
+			RefreshInterval: getEnvAsDuration("SEARCH_REFRESH_INTERVAL", 5*time.Minute),
+		},
+		Cart: CartConfig{
+			Secret: os.Getenv("CART_SECRET"),
+			TTL:    getEnvAsDuration("CART_TTL", 7*24*time.Hour),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		},
+		Admin: AdminConfig{
+			Tokens: getEnvAsMap("ADMIN_TOKENS"),
+		},
+		Inventory: InventoryConfig{
+			AuditLogSize:   getEnvAsInt("INVENTORY_AUDIT_LOG_SIZE", 10000),
+			ReservationTTL: getEnvAsDuration("INVENTORY_RESERVATION_TTL", 15*time.Minute),
+		},
+		Alert: AlertConfig{
+			Enabled:           getEnvAsBool("ALERT_ENABLED", false),
+			Interval:          getEnvAsDuration("ALERT_INTERVAL", 10*time.Minute),
+			LowStockThreshold: getEnvAsInt("ALERT_LOW_STOCK_THRESHOLD", 5),
+			SKUThresholds:     getEnvAsIntMap("ALERT_SKU_THRESHOLDS"),
+			SMTPAddr:          os.Getenv("ALERT_SMTP_ADDR"),
+			SMTPUsername:      os.Getenv("ALERT_SMTP_USERNAME"),
+			SMTPPassword:      os.Getenv("ALERT_SMTP_PASSWORD"),
+			SMTPFrom:          os.Getenv("ALERT_SMTP_FROM"),
+			SMTPTo:            getEnvAsSlice("ALERT_SMTP_TO", nil),
+			WebhookURL:        os.Getenv("ALERT_WEBHOOK_URL"),
+		},
+		Webhook: WebhookConfig{
+			Secrets:      getEnvAsSlice("SQUARESPACE_WEBHOOK_SECRETS", nil),
+			MaxAge:       getEnvAsDuration("SQUARESPACE_WEBHOOK_MAX_AGE", time.Hour),
+			EndpointURL:  os.Getenv("SQUARESPACE_WEBHOOK_URL"),
+			Topics:       getEnvAsSlice("SQUARESPACE_WEBHOOK_TOPICS", []string{"order.create", "order.update", "extension.uninstall"}),
+			RotateSecret: getEnvAsBool("SQUARESPACE_WEBHOOK_ROTATE_SECRET", false),
 		},
 	}
 
@@ -72,4 +221,51 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsMap parses a comma-separated list of name:value pairs.
+func getEnvAsMap(key string) map[string]string {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if ok && name != "" && value != "" {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// getEnvAsIntMap parses a comma-separated list of name:integer pairs,
+// skipping malformed entries.
+func getEnvAsIntMap(key string) map[string]int {
+	values := make(map[string]int)
+	for name, value := range getEnvAsMap(key) {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			values[name] = intValue
+		}
+	}
+	return values
+}
+
+// getEnvAsSlice parses a comma-separated list, dropping empty entries.
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
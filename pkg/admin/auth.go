@@ -0,0 +1,57 @@
+// Package admin guards back-office endpoints with static bearer tokens and
+// records which operator made each request.
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+const actorKey = "admin.actor"
+
+// Middleware admits requests whose Authorization header carries one of
+// tokens, keyed by operator name. With no tokens configured every request is
+// rejected.
+func Middleware(tokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			unauthorized(c)
+			return
+		}
+
+		// Compare against every token so timing doesn't reveal which matched
+		actor := ""
+		for name, candidate := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+				actor = name
+			}
+		}
+		if actor == "" {
+			unauthorized(c)
+			return
+		}
+
+		c.Set(actorKey, actor)
+		c.Next()
+	}
+}
+
+// Actor returns the operator name Middleware authenticated for c.
+func Actor(c *gin.Context) string {
+	return c.GetString(actorKey)
+}
+
+func unauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, models.APIResponse{
+		Error: &models.APIError{
+			Type:    "unauthorized",
+			Message: "A valid admin token is required",
+		},
+	})
+}
@@ -0,0 +1,189 @@
+// Package alert watches variant stock and notifies operators when it runs
+// low or out.
+package alert
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+// Kind says how short a variant is.
+type Kind string
+
+const (
+	KindLowStock   Kind = "low_stock"
+	KindOutOfStock Kind = "out_of_stock"
+)
+
+// severity orders kinds so only worsening stock raises a new alert.
+func (k Kind) severity() int {
+	switch k {
+	case KindLowStock:
+		return 1
+	case KindOutOfStock:
+		return 2
+	}
+	return 0
+}
+
+// Alert is sent to every notifier when a variant crosses a threshold.
+type Alert struct {
+	Kind      Kind      `json:"kind"`
+	ProductID string    `json:"productId"`
+	VariantID string    `json:"variantId"`
+	SKU       string    `json:"sku"`
+	Name      string    `json:"name"`
+	Quantity  int       `json:"quantity"`
+	Threshold int       `json:"threshold"`
+	At        time.Time `json:"at"`
+}
+
+// Thresholds decides when a variant counts as low. PerSKU overrides Default;
+// a threshold of zero only alerts when stock runs out.
+type Thresholds struct {
+	Default int
+	PerSKU  map[string]int
+}
+
+func (t Thresholds) forSKU(sku string) int {
+	if threshold, ok := t.PerSKU[sku]; ok {
+		return threshold
+	}
+	return t.Default
+}
+
+// Monitor polls product stock and alerts once per variant as stock worsens.
+// A variant is alerted again only after it recovers above its threshold or
+// gets worse, e.g. low then out.
+type Monitor struct {
+	api        *squarespace.Client
+	thresholds Thresholds
+	notifiers  []Notifier
+	interval   time.Duration
+
+	mu      sync.Mutex
+	alerted map[string]Kind
+}
+
+func NewMonitor(api *squarespace.Client, thresholds Thresholds, interval time.Duration, notifiers ...Notifier) *Monitor {
+	return &Monitor{
+		api:        api,
+		thresholds: thresholds,
+		notifiers:  notifiers,
+		interval:   interval,
+		alerted:    make(map[string]Kind),
+	}
+}
+
+// Check runs one poll, notifying for variants whose stock got worse, and
+// returns the alerts raised.
+func (m *Monitor) Check(ctx context.Context) ([]Alert, error) {
+	var alerts []Alert
+	now := time.Now().UTC()
+	seen := make(map[string]bool)
+
+	m.mu.Lock()
+	it := squarespace.NewProductIterator(ctx, m.api)
+	for it.Next() {
+		product := it.Value()
+		for _, variant := range product.Products {
+			seen[variant.ID] = true
+			quantity, tracked := trackedQuantity(variant.Stock)
+			if !tracked {
+				delete(m.alerted, variant.ID)
+				continue
+			}
+
+			threshold := m.thresholds.forSKU(variant.SKU)
+			var kind Kind
+			switch {
+			case quantity <= 0:
+				kind = KindOutOfStock
+			case quantity <= threshold:
+				kind = KindLowStock
+			}
+
+			previous := m.alerted[variant.ID]
+			if kind == "" {
+				delete(m.alerted, variant.ID)
+				continue
+			}
+			m.alerted[variant.ID] = kind
+			if kind.severity() <= previous.severity() {
+				continue
+			}
+			alerts = append(alerts, Alert{
+				Kind:      kind,
+				ProductID: product.ID,
+				VariantID: variant.ID,
+				SKU:       variant.SKU,
+				Name:      variant.Name,
+				Quantity:  quantity,
+				Threshold: threshold,
+				At:        now,
+			})
+		}
+	}
+	err := it.Err()
+	if err == nil {
+		// Forget variants that were deleted upstream
+		for id := range m.alerted {
+			if !seen[id] {
+				delete(m.alerted, id)
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	m.notify(ctx, alerts)
+	return alerts, err
+}
+
+// Run checks immediately and then every interval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if alerts, err := m.Check(ctx); err != nil {
+			log.Printf("alert: stock check failed: %v", err)
+		} else if len(alerts) > 0 {
+			log.Printf("alert: raised %d stock alert(s)", len(alerts))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify delivers every alert to every notifier. Failures are logged and not
+// retried, so a broken notifier can't cause repeat alerts through the others.
+func (m *Monitor) notify(ctx context.Context, alerts []Alert) {
+	for _, alert := range alerts {
+		for _, notifier := range m.notifiers {
+			if err := notifier.Notify(ctx, alert); err != nil {
+				log.Printf("alert: failed to send %s alert for %s: %v", alert.Kind, alert.VariantID, err)
+			}
+		}
+	}
+}
+
+// trackedQuantity returns the variant's quantity, or false when stock is
+// unlimited or untracked and can't run out.
+func trackedQuantity(stock models.ProductStock) (int, bool) {
+	if stock.Unlimited || !stock.TrackInventory {
+		return 0, false
+	}
+	if stock.Quantity == nil {
+		return 0, true
+	}
+	return *stock.Quantity, true
+}
@@ -0,0 +1,71 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+func TestCheckAlertsOncePerWorsening(t *testing.T) {
+	server := sstest.NewServer()
+	defer server.Close()
+	server.SeedDemoData()
+
+	var sent []string
+	failing := true
+	recorder := NotifierFunc(func(_ context.Context, alert Alert) error {
+		sent = append(sent, alert.VariantID+" "+string(alert.Kind))
+		if failing {
+			return errors.New("relay down")
+		}
+		return nil
+	})
+	monitor := NewMonitor(squarespace.NewClient(server.Config()), Thresholds{
+		Default: 3,
+		PerSKU:  map[string]int{"PRINT-A3": 12},
+	}, 0, recorder)
+
+	setStock := func(variantID string, quantity int) {
+		server.SetInventory(variantID, models.ProductStock{TrackInventory: true, Quantity: &quantity})
+	}
+
+	steps := []struct {
+		name    string
+		prepare func()
+		want    []string
+	}{
+		// A failing notifier mustn't make the next check alert again
+		{"first check", func() {}, []string{
+			"var-print-a3 low_stock",
+			"var-print-a2 low_stock",
+			"var-tee-black-m out_of_stock",
+		}},
+		{"nothing changed", func() { failing = false }, nil},
+		{"low to out", func() { setStock("var-print-a2", 0) }, []string{"var-print-a2 out_of_stock"}},
+		{"out to low", func() { setStock("var-print-a2", 1) }, nil},
+		{"recovered", func() { setStock("var-tee-black-m", 10) }, nil},
+		{"low after recovering", func() { setStock("var-tee-black-m", 2) }, []string{"var-tee-black-m low_stock"}},
+		{"per-SKU threshold recovered", func() { setStock("var-print-a3", 13) }, nil},
+		{"per-SKU threshold crossed", func() { setStock("var-print-a3", 12) }, []string{"var-print-a3 low_stock"}},
+	}
+
+	for _, step := range steps {
+		step.prepare()
+		sent = nil
+		alerts, err := monitor.Check(context.Background())
+		if err != nil {
+			t.Fatalf("%s: Check() error = %v", step.name, err)
+		}
+		if fmt.Sprint(sent) != fmt.Sprint(step.want) {
+			t.Errorf("%s: notified %v, want %v", step.name, sent, step.want)
+		}
+		if len(alerts) != len(step.want) {
+			t.Errorf("%s: Check() returned %d alerts, want %d", step.name, len(alerts), len(step.want))
+		}
+	}
+}
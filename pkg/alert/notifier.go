@@ -0,0 +1,189 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Notifier delivers an alert somewhere an operator will see it.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NotifierFunc adapts a function to Notifier.
+type NotifierFunc func(ctx context.Context, alert Alert) error
+
+func (f NotifierFunc) Notify(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// LogNotifier writes alerts to the standard logger.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, alert Alert) error {
+	log.Printf("alert: %s", summary(alert))
+	return nil
+}
+
+// defaultSMTPTimeout bounds a whole delivery when SMTPNotifier.Timeout is unset.
+const defaultSMTPTimeout = 30 * time.Second
+
+// SMTPNotifier emails alerts through an SMTP relay.
+type SMTPNotifier struct {
+	Addr string
+	Auth smtp.Auth
+	From string
+	To   []string
+	// Timeout bounds a whole delivery, so a hung relay can't stall the
+	// monitor. Zero means 30 seconds.
+	Timeout time.Duration
+}
+
+// Notify delivers alert as smtp.SendMail would, but gives up when ctx is done
+// or Timeout passes.
+func (n *SMTPNotifier) Notify(ctx context.Context, alert Alert) error {
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host, _, err := net.SplitHostPort(n.Addr)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The SMTP client takes no context, so bound its reads and writes with
+	// the deadline and unblock them if ctx is cancelled early
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.Auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(n.Auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.From); err != nil {
+		return err
+	}
+	for _, to := range n.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.message(alert)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message builds the email for alert. Product names and SKUs come from the
+// catalog, so they're stripped of control characters before going into
+// headers, where a CR or LF would start a header of their own.
+func (n *SMTPNotifier) message(alert Alert) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", stripControl(n.From))
+	fmt.Fprintf(&msg, "To: %s\r\n", stripControl(strings.Join(n.To, ", ")))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[store] "+stripControl(summary(alert))))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "Product: %s\r\nVariant: %s\r\nSKU: %s\r\nQuantity: %d\r\nThreshold: %d\r\nAt: %s\r\n",
+		stripControl(alert.ProductID), stripControl(alert.VariantID), stripControl(alert.SKU), alert.Quantity, alert.Threshold, alert.At.Format("2006-01-02 15:04:05 MST"))
+	return msg.Bytes()
+}
+
+// stripControl replaces control characters, including CR and LF, with spaces.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// WebhookNotifier POSTs each alert as JSON to URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func summary(alert Alert) string {
+	name := alert.Name
+	if alert.SKU != "" {
+		name += " (" + alert.SKU + ")"
+	}
+	if alert.Kind == KindOutOfStock {
+		return "Out of stock: " + name
+	}
+	return fmt.Sprintf("Low stock: %s has %d left", name, alert.Quantity)
+}
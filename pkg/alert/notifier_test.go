@@ -0,0 +1,172 @@
+package alert
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"mime"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSMTPMessageHeadersResistInjection(t *testing.T) {
+	notifier := &SMTPNotifier{From: "store@example.com", To: []string{"ops@example.com"}}
+
+	tests := []struct {
+		name        string
+		alert       Alert
+		wantSubject string
+	}{
+		{
+			"plain",
+			Alert{Kind: KindLowStock, Name: "Art Print", SKU: "PRINT-A3", Quantity: 2},
+			"[store] Low stock: Art Print (PRINT-A3) has 2 left",
+		},
+		{
+			"crlf in name",
+			Alert{Kind: KindOutOfStock, Name: "Print\r\nBcc: victim@example.com", SKU: "X\nY"},
+			"[store] Out of stock: Print  Bcc: victim@example.com (X Y)",
+		},
+		{
+			"non-ascii name",
+			Alert{Kind: KindOutOfStock, Name: "Café Mug\x00"},
+			"[store] Out of stock: Café Mug ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.alert.At = time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+			msg := notifier.message(tt.alert)
+
+			header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(msg))).ReadMIMEHeader()
+			if err != nil {
+				t.Fatalf("reading headers: %v\n%s", err, msg)
+			}
+			if len(header) != 4 {
+				t.Errorf("headers = %v, want exactly From, To, Subject and Content-Type", header)
+			}
+			if bcc := header.Get("Bcc"); bcc != "" {
+				t.Errorf("injected Bcc header %q", bcc)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if subject != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", subject, tt.wantSubject)
+			}
+
+			_, body, _ := strings.Cut(string(msg), "\r\n\r\n")
+			if strings.Count(body, "\n") != 6 {
+				t.Errorf("body has extra lines:\n%s", body)
+			}
+		})
+	}
+}
+
+// fakeRelay serves one SMTP session on a local port, sending each message it
+// accepts on the returned channel. A hung relay accepts the connection and
+// never answers.
+func fakeRelay(t *testing.T, hung bool) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if hung {
+			// Hold the connection open until the test ends
+			conn.Read(make([]byte, 1))
+			return
+		}
+
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 relay.test ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb, _, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				text.PrintfLine("250 OK")
+			case "DATA":
+				text.PrintfLine("354 Go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				received <- string(data)
+				text.PrintfLine("250 Queued")
+			case "QUIT":
+				text.PrintfLine("221 Bye")
+				return
+			default:
+				text.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTPNotifierDelivers(t *testing.T) {
+	addr, received := fakeRelay(t, false)
+	notifier := &SMTPNotifier{Addr: addr, From: "store@example.com", To: []string{"ops@example.com"}}
+
+	alert := Alert{Kind: KindOutOfStock, Name: "Art Print", SKU: "PRINT-A3"}
+	if err := notifier.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	select {
+	case msg := <-received:
+		if !strings.Contains(msg, "Subject: [store] Out of stock: Art Print (PRINT-A3)") {
+			t.Errorf("message = %q, want the alert subject", msg)
+		}
+	default:
+		t.Fatal("relay received no message")
+	}
+}
+
+func TestSMTPNotifierGivesUpOnHungRelay(t *testing.T) {
+	addr, _ := fakeRelay(t, true)
+	alert := Alert{Kind: KindOutOfStock, Name: "Art Print"}
+
+	tests := []struct {
+		name     string
+		notifier *SMTPNotifier
+		ctx      func() (context.Context, context.CancelFunc)
+	}{
+		{"timeout", &SMTPNotifier{Addr: addr, To: []string{"ops@example.com"}, Timeout: 50 * time.Millisecond}, func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}},
+		{"context", &SMTPNotifier{Addr: addr, To: []string{"ops@example.com"}}, func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 50*time.Millisecond)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			start := time.Now()
+			if err := tt.notifier.Notify(ctx, alert); err == nil {
+				t.Fatal("Notify() error = nil, want a timeout")
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Notify() took %v, want it to give up after 50ms", elapsed)
+			}
+		})
+	}
+}
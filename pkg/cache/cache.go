@@ -0,0 +1,190 @@
+// Package cache implements a read-through cache with stale-while-revalidate
+// and stale-if-error semantics over a pluggable byte store.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Store is the backend a Cache keeps entries in. Its shape matches Redis
+// GET / SET EX / DEL so a Redis adapter can be dropped in for the default LRU.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Policy controls how long an entry is served.
+type Policy struct {
+	// TTL is how long an entry is fresh.
+	TTL time.Duration
+	// StaleWhileRevalidate is how long past TTL an entry is still served
+	// while a background refresh runs.
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is how long past TTL an entry is served when the loader fails.
+	StaleIfError time.Duration
+}
+
+func (p Policy) retention() time.Duration {
+	if p.StaleIfError > p.StaleWhileRevalidate {
+		return p.TTL + p.StaleIfError
+	}
+	return p.TTL + p.StaleWhileRevalidate
+}
+
+type Status string
+
+const (
+	StatusHit   Status = "HIT"
+	StatusMiss  Status = "MISS"
+	StatusStale Status = "STALE"
+)
+
+type statusContextKey struct{}
+
+// Track returns a context that records the status of cache lookups made with
+// it. When several lookups share the context, e.g. while walking every page of
+// a listing, it reports the least fresh of them.
+func Track(ctx context.Context) (context.Context, *Status) {
+	status := new(Status)
+	return context.WithValue(ctx, statusContextKey{}, status), status
+}
+
+func record(ctx context.Context, status Status) {
+	if tracked, ok := ctx.Value(statusContextKey{}).(*Status); ok && status.staleness() >= tracked.staleness() {
+		*tracked = status
+	}
+}
+
+func (s Status) staleness() int {
+	switch s {
+	case StatusHit:
+		return 1
+	case StatusStale:
+		return 2
+	case StatusMiss:
+		return 3
+	}
+	return 0
+}
+
+type entry struct {
+	Value    json.RawMessage `json:"value"`
+	StoredAt time.Time       `json:"storedAt"`
+}
+
+type Cache struct {
+	store Store
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+func New(store Store) *Cache {
+	return &Cache{
+		store:      store,
+		refreshing: make(map[string]bool),
+	}
+}
+
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	return c.store.Delete(ctx, key)
+}
+
+// Fetch returns the cached value for key, calling load on a miss. Stale
+// entries are served while load refreshes them in the background, and
+// returned in place of load's error while within policy.StaleIfError.
+func Fetch[T any](ctx context.Context, c *Cache, key string, policy Policy, load func(context.Context) (T, error)) (T, error) {
+	var value T
+
+	age, found := c.lookup(ctx, key, &value)
+	switch {
+	case found && age < policy.TTL:
+		record(ctx, StatusHit)
+		return value, nil
+	case found && age < policy.TTL+policy.StaleWhileRevalidate:
+		record(ctx, StatusStale)
+		c.refresh(ctx, key, policy, func(ctx context.Context) (interface{}, error) { return load(ctx) })
+		return value, nil
+	}
+
+	loaded, err := load(ctx)
+	if err != nil {
+		if found && age < policy.TTL+policy.StaleIfError {
+			record(ctx, StatusStale)
+			return value, nil
+		}
+		return loaded, err
+	}
+
+	record(ctx, StatusMiss)
+	c.save(ctx, key, policy, loaded)
+	return loaded, nil
+}
+
+func (c *Cache) lookup(ctx context.Context, key string, target interface{}) (time.Duration, bool) {
+	data, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		log.Printf("cache: get %s: %v", key, err)
+		return 0, false
+	}
+	if !ok {
+		return 0, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return 0, false
+	}
+	if err := json.Unmarshal(e.Value, target); err != nil {
+		return 0, false
+	}
+	return time.Since(e.StoredAt), true
+}
+
+func (c *Cache) save(ctx context.Context, key string, policy Policy, value interface{}) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("cache: encode %s: %v", key, err)
+		return
+	}
+	data, err := json.Marshal(entry{Value: raw, StoredAt: time.Now()})
+	if err != nil {
+		return
+	}
+	if err := c.store.Set(ctx, key, data, policy.retention()); err != nil {
+		log.Printf("cache: set %s: %v", key, err)
+	}
+}
+
+// refresh reloads key in the background, at most once at a time per key.
+func (c *Cache) refresh(ctx context.Context, key string, policy Policy, load func(context.Context) (interface{}, error)) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	// Detach from the request so the refresh outlives it
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		value, err := load(ctx)
+		if err != nil {
+			log.Printf("cache: refresh %s: %v", key, err)
+			return
+		}
+		c.save(ctx, key, policy, value)
+	}()
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// seed stores value under key as if it had been cached age ago.
+func seed(t *testing.T, store Store, key string, value interface{}, age time.Duration) {
+	t.Helper()
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(entry{Value: raw, StoredAt: time.Now().Add(-age)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(context.Background(), key, data, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	policy := Policy{TTL: time.Minute, StaleWhileRevalidate: time.Minute, StaleIfError: 10 * time.Minute}
+	errUpstream := errors.New("upstream down")
+
+	tests := []struct {
+		name    string
+		age     time.Duration // age of the seeded entry; zero seeds nothing
+		loadErr error
+		want    string
+		status  Status
+		wantErr error
+		loads   int32
+	}{
+		{"miss loads", 0, nil, "loaded", StatusMiss, nil, 1},
+		{"fresh hit", 30 * time.Second, nil, "cached", StatusHit, nil, 0},
+		{"stale if error", 5 * time.Minute, errUpstream, "cached", StatusStale, nil, 1},
+		{"too stale to serve on error", 20 * time.Minute, errUpstream, "", "", errUpstream, 1},
+		{"miss with error", 0, errUpstream, "", "", errUpstream, 1},
+		{"expired entry reloads", 5 * time.Minute, nil, "loaded", StatusMiss, nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewLRU(8)
+			if tt.age > 0 {
+				seed(t, store, "key", "cached", tt.age)
+			}
+			c := New(store)
+
+			var loads int32
+			ctx, status := Track(context.Background())
+			got, err := Fetch(ctx, c, "key", policy, func(context.Context) (string, error) {
+				atomic.AddInt32(&loads, 1)
+				if tt.loadErr != nil {
+					return "", tt.loadErr
+				}
+				return "loaded", nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Fetch() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want || *status != tt.status {
+				t.Errorf("Fetch() = %q, %s; want %q, %s", got, *status, tt.want, tt.status)
+			}
+			if loads != tt.loads {
+				t.Errorf("load called %d times, want %d", loads, tt.loads)
+			}
+		})
+	}
+}
+
+func TestFetchRevalidatesStaleEntryOnce(t *testing.T) {
+	store := NewLRU(8)
+	seed(t, store, "key", "old", 90*time.Second)
+	c := New(store)
+	policy := Policy{TTL: time.Minute, StaleWhileRevalidate: time.Minute}
+
+	var loads int32
+	release := make(chan struct{})
+	load := func(context.Context) (string, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "new", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, status := Track(context.Background())
+			got, err := Fetch(ctx, c, "key", policy, load)
+			if err != nil || got != "old" || *status != StatusStale {
+				t.Errorf("Fetch() = %q, %s, %v; want the stale value", got, *status, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		ctx, status := Track(context.Background())
+		got, _ := Fetch(ctx, c, "key", policy, load)
+		if got == "new" && *status == StatusHit {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Fetch() = %q, %s; the refreshed value never landed", got, *status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if loads != 1 {
+		t.Errorf("load called %d times, want one background refresh", loads)
+	}
+}
+
+func TestTrackReportsLeastFresh(t *testing.T) {
+	ctx, status := Track(context.Background())
+	for _, s := range []Status{StatusHit, StatusMiss, StatusStale, StatusHit} {
+		record(ctx, s)
+	}
+	if *status != StatusMiss {
+		t.Errorf("status = %s, want MISS", *status)
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2)
+	lru.Set(ctx, "a", []byte("1"), 0)
+	lru.Set(ctx, "b", []byte("2"), 0)
+	// Touch a so b is the least recently used
+	if _, ok, _ := lru.Get(ctx, "a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+	lru.Set(ctx, "c", []byte("3"), 0)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := lru.Get(ctx, key); ok != want {
+			t.Errorf("Get(%s) found = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2)
+	lru.Set(ctx, "short", []byte("1"), time.Millisecond)
+	lru.Set(ctx, "forever", []byte("2"), 0)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok, _ := lru.Get(ctx, "short"); ok {
+		t.Error("Get(short) found an expired entry")
+	}
+	if _, ok, _ := lru.Get(ctx, "forever"); !ok {
+		t.Error("Get(forever) missed an entry without a TTL")
+	}
+	if lru.order.Len() != 1 {
+		t.Errorf("LRU holds %d entries, want the expired one dropped", lru.order.Len())
+	}
+}
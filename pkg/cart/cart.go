@@ -0,0 +1,83 @@
+// Package cart implements server-side shopping carts priced from the
+// Squarespace catalog.
+package cart
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+var (
+	ErrNotFound         = errors.New("cart not found")
+	ErrInvalidToken     = errors.New("invalid cart token")
+	ErrItemNotFound     = errors.New("cart item not found")
+	ErrVariantNotFound  = errors.New("product variant not found")
+	ErrInvalidQuantity  = errors.New("quantity must be between 1 and 999")
+	ErrNotPurchasable   = errors.New("product variant has no price")
+	ErrCurrencyMismatch = errors.New("item currency does not match cart currency")
+)
+
+// StockError reports that a variant can't supply the requested quantity.
+type StockError struct {
+	VariantID string `json:"variantId"`
+	Requested int    `json:"requested"`
+	Available int    `json:"available"`
+}
+
+func (e *StockError) Error() string {
+	return fmt.Sprintf("insufficient stock for variant %s: requested %d, available %d", e.VariantID, e.Requested, e.Available)
+}
+
+// AvailabilityError lists every line that can no longer be supplied.
+type AvailabilityError struct {
+	Items []StockError
+}
+
+func (e *AvailabilityError) Error() string {
+	return fmt.Sprintf("%d cart item(s) are no longer available in the requested quantity", len(e.Items))
+}
+
+// PriceChange describes a line whose unit price moved since it was added.
+type PriceChange struct {
+	ItemID   string       `json:"itemId"`
+	Previous models.Money `json:"previous"`
+	Current  models.Money `json:"current"`
+}
+
+const maxQuantity = 999
+
+type Cart struct {
+	ID        string       `json:"-"`
+	Token     string       `json:"token"`
+	Items     []Item       `json:"items"`
+	Currency  string       `json:"currency,omitempty"`
+	Subtotal  models.Money `json:"subtotal"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+}
+
+type Item struct {
+	ID             string                      `json:"id"`
+	ProductID      string                      `json:"productId"`
+	VariantID      string                      `json:"variantId"`
+	SKU            string                      `json:"sku"`
+	Name           string                      `json:"name"`
+	Quantity       int                         `json:"quantity"`
+	UnitPrice      models.Money                `json:"unitPrice"`
+	TotalPrice     models.Money                `json:"totalPrice"`
+	OnSale         bool                        `json:"onSale"`
+	Customizations []models.OrderCustomization `json:"customizations,omitempty"`
+}
+
+func (c *Cart) item(itemID string) (int, bool) {
+	for i, item := range c.Items {
+		if item.ID == itemID {
+			return i, true
+		}
+	}
+	return 0, false
+}
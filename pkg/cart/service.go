@@ -0,0 +1,357 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/money"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+type AddItemRequest struct {
+	ProductID      string                      `json:"productId" binding:"required"`
+	VariantID      string                      `json:"variantId" binding:"required"`
+	Quantity       int                         `json:"quantity"`
+	Customizations []models.OrderCustomization `json:"customizations,omitempty"`
+}
+
+// Service validates cart changes against the catalog and persists them.
+// Mutations of the same cart are serialized so concurrent requests can't lose
+// updates; different carts never wait on each other.
+type Service struct {
+	api    squarespace.API
+	store  Store
+	signer signer
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	locks map[string]*cartLock
+}
+
+// cartLock serializes mutations of one cart. refs counts the callers holding
+// or waiting for it, so it can be dropped once nobody needs it.
+type cartLock struct {
+	sync.Mutex
+	refs int
+}
+
+func NewService(api squarespace.API, store Store, secret []byte, ttl time.Duration) *Service {
+	return &Service{
+		api:    api,
+		store:  store,
+		signer: signer{secret: secret},
+		ttl:    ttl,
+		locks:  make(map[string]*cartLock),
+	}
+}
+
+func (s *Service) Create(ctx context.Context) (*Cart, error) {
+	now := time.Now()
+	cart := &Cart{
+		ID:        newID(),
+		Items:     []Item{},
+		CreatedAt: now,
+	}
+	cart.Token = s.signer.sign(cart.ID)
+	return cart, s.save(ctx, cart, now)
+}
+
+func (s *Service) Get(ctx context.Context, token string) (*Cart, error) {
+	id, err := s.signer.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cart.Token = token
+	return cart, nil
+}
+
+func (s *Service) AddItem(ctx context.Context, token string, req AddItemRequest) (*Cart, error) {
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 1 || req.Quantity > maxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	return s.update(ctx, token, func(cart *Cart) error {
+		// Identical uncustomized lines merge into one
+		index, merge := -1, len(req.Customizations) == 0
+		for i, item := range cart.Items {
+			if merge && item.VariantID == req.VariantID && len(item.Customizations) == 0 {
+				index = i
+				break
+			}
+		}
+
+		quantity := req.Quantity
+		if index >= 0 {
+			quantity += cart.Items[index].Quantity
+			if quantity > maxQuantity {
+				return ErrInvalidQuantity
+			}
+		}
+
+		item, err := s.priceItem(ctx, req.ProductID, req.VariantID, quantity)
+		if err != nil {
+			return err
+		}
+		if cart.Currency != "" && item.UnitPrice.Currency != cart.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		if index >= 0 {
+			item.ID = cart.Items[index].ID
+			cart.Items[index] = item
+		} else {
+			item.ID = newID()[:12]
+			item.Customizations = req.Customizations
+			cart.Items = append(cart.Items, item)
+		}
+		return nil
+	})
+}
+
+func (s *Service) UpdateItem(ctx context.Context, token, itemID string, quantity int) (*Cart, error) {
+	if quantity < 1 || quantity > maxQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	return s.update(ctx, token, func(cart *Cart) error {
+		index, ok := cart.item(itemID)
+		if !ok {
+			return ErrItemNotFound
+		}
+		current := cart.Items[index]
+
+		item, err := s.priceItem(ctx, current.ProductID, current.VariantID, quantity)
+		if err != nil {
+			return err
+		}
+		item.ID = current.ID
+		item.Customizations = current.Customizations
+		cart.Items[index] = item
+		return nil
+	})
+}
+
+func (s *Service) RemoveItem(ctx context.Context, token, itemID string) (*Cart, error) {
+	return s.update(ctx, token, func(cart *Cart) error {
+		index, ok := cart.item(itemID)
+		if !ok {
+			return ErrItemNotFound
+		}
+		cart.Items = append(cart.Items[:index], cart.Items[index+1:]...)
+		return nil
+	})
+}
+
+func (s *Service) Clear(ctx context.Context, token string) (*Cart, error) {
+	return s.update(ctx, token, func(cart *Cart) error {
+		cart.Items = []Item{}
+		return nil
+	})
+}
+
+// Reprice re-prices every line from current catalog pricing and re-checks
+// stock, saving the refreshed cart. It returns the lines whose price changed;
+// when any line is out of stock it returns an *AvailabilityError instead.
+func (s *Service) Reprice(ctx context.Context, token string) (*Cart, []PriceChange, error) {
+	var changes []PriceChange
+	cart, err := s.update(ctx, token, func(cart *Cart) error {
+		var unavailable []StockError
+		for i, current := range cart.Items {
+			item, err := s.priceItem(ctx, current.ProductID, current.VariantID, current.Quantity)
+			var stockErr *StockError
+			if errors.As(err, &stockErr) {
+				unavailable = append(unavailable, *stockErr)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if !samePrice(item.UnitPrice, current.UnitPrice) {
+				changes = append(changes, PriceChange{
+					ItemID:   current.ID,
+					Previous: current.UnitPrice,
+					Current:  item.UnitPrice,
+				})
+			}
+			item.ID = current.ID
+			item.Customizations = current.Customizations
+			cart.Items[i] = item
+		}
+		if len(unavailable) > 0 {
+			return &AvailabilityError{Items: unavailable}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cart, changes, nil
+}
+
+// Delete discards a cart, e.g. once it has been checked out.
+func (s *Service) Delete(ctx context.Context, token string) error {
+	id, err := s.signer.verify(token)
+	if err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, id)
+}
+
+func (s *Service) update(ctx context.Context, token string, mutate func(*Cart) error) (*Cart, error) {
+	id, err := s.signer.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	unlock := s.lock(id)
+	defer unlock()
+
+	cart, err := s.Get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := mutate(cart); err != nil {
+		return nil, err
+	}
+	return cart, s.save(ctx, cart, time.Now())
+}
+
+// lock takes the lock for cart id, returning the function that releases it.
+// Pricing calls Squarespace, so only requests for the same cart should wait.
+func (s *Service) lock(id string) (unlock func()) {
+	s.mu.Lock()
+	l, ok := s.locks[id]
+	if !ok {
+		l = &cartLock{}
+		s.locks[id] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.locks, id)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Service) save(ctx context.Context, cart *Cart, now time.Time) error {
+	var subtotal money.Money
+	for _, item := range cart.Items {
+		total, err := money.FromModel(item.TotalPrice)
+		if err != nil {
+			return err
+		}
+		if subtotal, err = subtotal.Add(total); err != nil {
+			return ErrCurrencyMismatch
+		}
+	}
+	cart.Currency = subtotal.Currency()
+	cart.Subtotal = subtotal.Model()
+	cart.UpdatedAt = now
+	cart.ExpiresAt = now.Add(s.ttl)
+	return s.store.Save(ctx, cart)
+}
+
+// priceItem looks up a variant, checks it can supply quantity and prices the
+// line from its current pricing.
+func (s *Service) priceItem(ctx context.Context, productID, variantID string, quantity int) (Item, error) {
+	product, variant, err := LookupVariant(ctx, s.api, productID, variantID)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := CheckStock(variant, quantity); err != nil {
+		return Item{}, err
+	}
+
+	unitPrice, ok := UnitPrice(variant)
+	if !ok {
+		return Item{}, ErrNotPurchasable
+	}
+	unit, err := money.FromModel(unitPrice)
+	if err != nil {
+		return Item{}, err
+	}
+	total, err := unit.Mul(int64(quantity))
+	if err != nil {
+		return Item{}, err
+	}
+
+	return Item{
+		ProductID:  product.ID,
+		VariantID:  variant.ID,
+		SKU:        variant.SKU,
+		Name:       variant.Name,
+		Quantity:   quantity,
+		UnitPrice:  unitPrice,
+		TotalPrice: total.Model(),
+		OnSale:     variant.Pricing.OnSale && variant.Pricing.SalePrice != nil,
+	}, nil
+}
+
+// LookupVariant fetches a product and finds one of its variants.
+func LookupVariant(ctx context.Context, api squarespace.API, productID, variantID string) (*models.Product, *models.ProductVariant, error) {
+	product, err := api.GetProduct(ctx, productID)
+	if errors.Is(err, squarespace.ErrNotFound) {
+		return nil, nil, ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range product.Products {
+		if product.Products[i].ID == variantID {
+			return product, &product.Products[i], nil
+		}
+	}
+	return nil, nil, ErrVariantNotFound
+}
+
+// CheckStock returns a *StockError when variant can't supply quantity.
+func CheckStock(variant *models.ProductVariant, quantity int) error {
+	stock := variant.Stock
+	if stock.Unlimited || stock.AllowBackorder || !stock.TrackInventory {
+		return nil
+	}
+	available := 0
+	if stock.Quantity != nil {
+		available = *stock.Quantity
+	}
+	if quantity > available {
+		return &StockError{VariantID: variant.ID, Requested: quantity, Available: available}
+	}
+	return nil
+}
+
+// UnitPrice is the sale price when the variant is on sale, otherwise its base price.
+func UnitPrice(variant *models.ProductVariant) (models.Money, bool) {
+	if variant.Pricing.OnSale && variant.Pricing.SalePrice != nil {
+		return *variant.Pricing.SalePrice, true
+	}
+	if variant.Pricing.BasePrice != nil {
+		return *variant.Pricing.BasePrice, true
+	}
+	return models.Money{}, false
+}
+
+func samePrice(a, b models.Money) bool {
+	x, errA := money.FromModel(a)
+	y, errB := money.FromModel(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return x.Equal(y)
+}
@@ -0,0 +1,87 @@
+package cart
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+func newTestService(t *testing.T) (*sstest.Server, *Service) {
+	t.Helper()
+	server := sstest.NewServer()
+	t.Cleanup(server.Close)
+	server.SeedDemoData()
+	return server, NewService(squarespace.NewClient(server.Config()), NewMemoryStore(), []byte("test-secret"), time.Hour)
+}
+
+func TestConcurrentAddsToOneCartAreNotLost(t *testing.T) {
+	_, service := newTestService(t)
+	ctx := context.Background()
+	cart, err := service.Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.AddItem(ctx, cart.Token, AddItemRequest{ProductID: "prod-ebook", VariantID: "var-ebook"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	cart, err = service.Get(ctx, cart.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].Quantity != 5 {
+		t.Fatalf("cart items = %+v, want one line of 5", cart.Items)
+	}
+}
+
+func TestCartsDoNotWaitOnEachOther(t *testing.T) {
+	server, service := newTestService(t)
+	ctx := context.Background()
+
+	const latency = 200 * time.Millisecond
+	server.SetLatency(latency)
+
+	const carts = 4
+	tokens := make([]string, carts)
+	for i := range tokens {
+		cart, err := service.Create(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[i] = cart.Token
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, token := range tokens {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			if _, err := service.AddItem(ctx, token, AddItemRequest{ProductID: "prod-ebook", VariantID: "var-ebook"}); err != nil {
+				t.Error(err)
+			}
+		}(token)
+	}
+	wg.Wait()
+
+	// Serialized pricing would take carts x latency
+	if elapsed := time.Since(start); elapsed >= carts*latency {
+		t.Errorf("adding to %d carts took %v, want them priced concurrently", carts, elapsed)
+	}
+	if len(service.locks) != 0 {
+		t.Errorf("%d cart locks left behind", len(service.locks))
+	}
+}
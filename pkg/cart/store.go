@@ -0,0 +1,71 @@
+package cart
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store persists carts. Entries past their expiry must not be returned.
+type Store interface {
+	Get(ctx context.Context, id string) (*Cart, error)
+	Save(ctx context.Context, cart *Cart) error
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps carts in process memory, dropping them once expired.
+type MemoryStore struct {
+	mu    sync.Mutex
+	carts map[string]Cart
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{carts: make(map[string]Cart)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cart, ok := s.carts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if time.Now().After(cart.ExpiresAt) {
+		delete(s.carts, id)
+		return nil, ErrNotFound
+	}
+	cart.Items = append([]Item(nil), cart.Items...)
+	return &cart, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, cart *Cart) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *cart
+	saved.Items = append([]Item(nil), cart.Items...)
+	s.carts[cart.ID] = saved
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.carts, id)
+	return nil
+}
+
+// Sweep removes expired carts.
+func (s *MemoryStore) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, cart := range s.carts {
+		if now.After(cart.ExpiresAt) {
+			delete(s.carts, id)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cart
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// signer issues opaque cart tokens of the form <id>.<hmac>, so a cart can
+// only be addressed by whoever was handed its token.
+type signer struct {
+	secret []byte
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("cart: failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+func (s signer) sign(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s signer) verify(token string) (string, error) {
+	id, _, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return "", ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(s.sign(id)), []byte(token)) {
+		return "", ErrInvalidToken
+	}
+	return id, nil
+}
@@ -0,0 +1,172 @@
+package catalog
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+const defaultPageSize = 50
+
+// MirrorClient serves catalog reads from a Mirror, falling back to the
+// wrapped API until the first sync completes or when a product is missing.
+// Everything else passes straight through.
+type MirrorClient struct {
+	squarespace.API
+
+	mirror *Mirror
+}
+
+func NewMirrorClient(api squarespace.API, mirror *Mirror) *MirrorClient {
+	return &MirrorClient{API: api, mirror: mirror}
+}
+
+func (c *MirrorClient) ready() bool {
+	state, err := c.mirror.State()
+	if err != nil {
+		log.Printf("catalog: %v", err)
+		return false
+	}
+	return !state.LastSync.IsZero()
+}
+
+func (c *MirrorClient) GetProducts(ctx context.Context, options ...squarespace.ProductOption) ([]models.Product, *models.Pagination, error) {
+	if !c.ready() {
+		return c.API.GetProducts(ctx, options...)
+	}
+
+	opts := &squarespace.ProductOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	products, err := c.mirror.Products()
+	if err != nil {
+		return nil, nil, err
+	}
+	page, pagination := queryProducts(products, opts)
+	return page, pagination, nil
+}
+
+func (c *MirrorClient) GetProduct(ctx context.Context, productID string) (*models.Product, error) {
+	product, ok, err := c.mirror.Product(productID)
+	if err != nil || !ok {
+		return c.API.GetProduct(ctx, productID)
+	}
+	return product, nil
+}
+
+func (c *MirrorClient) GetProductVariants(ctx context.Context, productID string) ([]models.ProductVariant, error) {
+	product, err := c.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return product.Products, nil
+}
+
+// queryProducts applies the filters, sort and paging Squarespace would.
+// Cursors are offsets into the filtered result, prefixed with "m". Any other
+// cursor, e.g. one Squarespace issued before the mirror took over, restarts
+// from the first page.
+func queryProducts(products []models.Product, opts *squarespace.ProductOptions) ([]models.Product, *models.Pagination) {
+	filtered := products[:0:0]
+	for _, product := range products {
+		if opts.Category != "" && !contains(product.Categories, opts.Category) {
+			continue
+		}
+		if opts.Tag != "" && !contains(product.Tags, opts.Tag) {
+			continue
+		}
+		if !opts.ModifiedAfter.IsZero() && product.SystemData.ModifiedOn <= opts.ModifiedAfter.UnixMilli() {
+			continue
+		}
+		if !opts.ModifiedBefore.IsZero() && product.SystemData.ModifiedOn >= opts.ModifiedBefore.UnixMilli() {
+			continue
+		}
+		filtered = append(filtered, product)
+	}
+
+	sortProducts(filtered, opts.Sort)
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	offset := max(opts.Offset, 0)
+	if opts.Cursor != "" {
+		offset = 0
+		if n, err := strconv.Atoi(strings.TrimPrefix(opts.Cursor, "m")); err == nil && n >= 0 && strings.HasPrefix(opts.Cursor, "m") {
+			offset = n
+		}
+	}
+
+	total := len(filtered)
+	pagination := &models.Pagination{TotalResults: &total}
+	if offset >= total {
+		return []models.Product{}, pagination
+	}
+	// A page never needs to be longer than the result, and clamping keeps
+	// offset+limit from overflowing.
+	limit = min(limit, total)
+	end := offset + limit
+	if end < total {
+		next := "m" + strconv.Itoa(end)
+		pagination.NextPage = &next
+	} else {
+		end = total
+	}
+	if offset > 0 {
+		prev := "m" + strconv.Itoa(max(offset-limit, 0))
+		pagination.PrevPage = &prev
+	}
+	return filtered[offset:end], pagination
+}
+
+func sortProducts(products []models.Product, field string) {
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	var less func(a, b models.Product) bool
+	switch field {
+	case "name":
+		less = func(a, b models.Product) bool {
+			return strings.ToLower(productName(a)) < strings.ToLower(productName(b))
+		}
+	case "createdOn":
+		less = func(a, b models.Product) bool { return a.SystemData.CreatedOn < b.SystemData.CreatedOn }
+	case "modifiedOn":
+		less = func(a, b models.Product) bool { return a.SystemData.ModifiedOn < b.SystemData.ModifiedOn }
+	case "publishedOn":
+		less = func(a, b models.Product) bool { return a.SystemData.PublishedOn < b.SystemData.PublishedOn }
+	default:
+		return
+	}
+
+	sort.SliceStable(products, func(i, j int) bool {
+		if desc {
+			return less(products[j], products[i])
+		}
+		return less(products[i], products[j])
+	})
+}
+
+func productName(product models.Product) string {
+	if len(product.Products) > 0 {
+		return product.Products[0].Name
+	}
+	return ""
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
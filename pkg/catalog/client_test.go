@@ -0,0 +1,53 @@
+package catalog
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+func TestQueryProductsPaging(t *testing.T) {
+	products := make([]models.Product, 5)
+	for i := range products {
+		products[i].ID = fmt.Sprintf("prod-%d", i)
+	}
+
+	tests := []struct {
+		name    string
+		opts    squarespace.ProductOptions
+		wantIDs []string
+		next    string
+	}{
+		{"first page", squarespace.ProductOptions{Limit: 2}, []string{"prod-0", "prod-1"}, "m2"},
+		{"offset", squarespace.ProductOptions{Limit: 2, Offset: 3}, []string{"prod-3", "prod-4"}, ""},
+		{"negative offset starts at zero", squarespace.ProductOptions{Limit: 2, Offset: -3}, []string{"prod-0", "prod-1"}, "m2"},
+		{"mirror cursor", squarespace.ProductOptions{Limit: 2, Cursor: "m2"}, []string{"prod-2", "prod-3"}, "m4"},
+		{"upstream cursor restarts", squarespace.ProductOptions{Limit: 2, Cursor: "eyJvZmZzZXQiOjJ9"}, []string{"prod-0", "prod-1"}, "m2"},
+		{"negative cursor restarts", squarespace.ProductOptions{Limit: 2, Cursor: "m-2"}, []string{"prod-0", "prod-1"}, "m2"},
+		{"past the end", squarespace.ProductOptions{Limit: 2, Offset: 10}, nil, ""},
+		{"huge limit", squarespace.ProductOptions{Limit: math.MaxInt, Offset: 1}, []string{"prod-1", "prod-2", "prod-3", "prod-4"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, pagination := queryProducts(products, &tt.opts)
+			var ids []string
+			for _, product := range page {
+				ids = append(ids, product.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("page = %v, want %v", ids, tt.wantIDs)
+			}
+			next := ""
+			if pagination.NextPage != nil {
+				next = *pagination.NextPage
+			}
+			if next != tt.next {
+				t.Errorf("next cursor = %q, want %q", next, tt.next)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"strings"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/money"
+)
+
+// priceRangeBounds are the lower bounds of the price facet buckets; the last
+// bucket is open-ended.
+var priceRangeBounds = []float64{0, 25, 50, 100}
+
+type Facets struct {
+	Categories  map[string]int            `json:"categories"`
+	Tags        map[string]int            `json:"tags"`
+	PriceRanges []PriceRangeCount         `json:"priceRanges"`
+	Attributes  map[string]map[string]int `json:"attributes"`
+	InStock     int                       `json:"inStock"`
+	OnSale      int                       `json:"onSale"`
+}
+
+type PriceRangeCount struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int      `json:"count"`
+}
+
+// Aggregate counts products per facet value. A product counts once per
+// value even when several of its variants share it.
+func Aggregate(products []models.Product) Facets {
+	f := Facets{
+		Categories:  make(map[string]int),
+		Tags:        make(map[string]int),
+		PriceRanges: make([]PriceRangeCount, len(priceRangeBounds)),
+		Attributes:  make(map[string]map[string]int),
+	}
+	for i, lower := range priceRangeBounds {
+		f.PriceRanges[i].Min = lower
+		if i+1 < len(priceRangeBounds) {
+			upper := priceRangeBounds[i+1]
+			f.PriceRanges[i].Max = &upper
+		}
+	}
+
+	for _, product := range products {
+		for _, category := range product.Categories {
+			f.Categories[category]++
+		}
+		for _, tag := range product.Tags {
+			f.Tags[tag]++
+		}
+
+		buckets := make(map[int]bool)
+		attributes := make(map[string]bool)
+		inStock, onSale := false, false
+		for _, variant := range product.Products {
+			if price, ok := EffectivePrice(variant); ok {
+				buckets[priceBucket(price)] = true
+			}
+			for name, value := range variantAttributes(variant) {
+				key := name + "\x00" + strings.ToLower(value)
+				if attributes[key] {
+					continue
+				}
+				attributes[key] = true
+				if f.Attributes[name] == nil {
+					f.Attributes[name] = make(map[string]int)
+				}
+				f.Attributes[name][value]++
+			}
+			inStock = inStock || InStock(variant.Stock)
+			onSale = onSale || variant.Pricing.OnSale
+		}
+
+		for bucket := range buckets {
+			f.PriceRanges[bucket].Count++
+		}
+		if inStock {
+			f.InStock++
+		}
+		if onSale {
+			f.OnSale++
+		}
+	}
+
+	return f
+}
+
+func priceBucket(price money.Money) int {
+	for i := len(priceRangeBounds) - 1; i > 0; i-- {
+		if withinBound(price, priceRangeBounds[i], -1) {
+			return i
+		}
+	}
+	return 0
+}
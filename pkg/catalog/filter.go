@@ -0,0 +1,208 @@
+package catalog
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/money"
+)
+
+// Server-side sort orders understood by Filter.
+const (
+	SortPriceAsc  = "price"
+	SortPriceDesc = "-price"
+	SortNewest    = "newest"
+	SortNameAsc   = "name"
+	SortNameDesc  = "-name"
+)
+
+// IsFilterSort reports whether sort is evaluated by Filter rather than upstream.
+func IsFilterSort(sort string) bool {
+	switch sort {
+	case SortPriceAsc, SortPriceDesc, SortNewest, SortNameAsc, SortNameDesc:
+		return true
+	}
+	return false
+}
+
+// Filter selects products with at least one variant satisfying every
+// variant-level condition.
+type Filter struct {
+	MinPrice   *float64
+	MaxPrice   *float64
+	InStock    bool
+	OnSale     bool
+	Attributes map[string]string
+	Sort       string
+}
+
+func (f Filter) Active() bool {
+	return f.MinPrice != nil || f.MaxPrice != nil || f.InStock || f.OnSale || len(f.Attributes) > 0 || IsFilterSort(f.Sort)
+}
+
+// Apply returns the matching products in the filter's sort order. Products
+// without a price sort after priced ones in either price order.
+func (f Filter) Apply(products []models.Product) []models.Product {
+	matched := make([]models.Product, 0, len(products))
+	prices := make(map[string]money.Money, len(products))
+	for _, product := range products {
+		price, priced, ok := f.match(product)
+		if !ok {
+			continue
+		}
+		matched = append(matched, product)
+		if priced {
+			prices[product.ID] = price
+		}
+	}
+
+	desc := f.Sort == SortPriceDesc || f.Sort == SortNameDesc
+	var less func(a, b models.Product) bool
+	switch f.Sort {
+	case SortPriceAsc, SortPriceDesc:
+		less = func(a, b models.Product) bool {
+			priceA, pricedA := prices[a.ID]
+			priceB, pricedB := prices[b.ID]
+			if pricedA != pricedB {
+				return pricedA
+			}
+			cmp, err := priceA.Cmp(priceB)
+			if err != nil {
+				return false
+			}
+			if desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+	case SortNewest:
+		less = func(a, b models.Product) bool { return a.SystemData.PublishedOn > b.SystemData.PublishedOn }
+	case SortNameAsc, SortNameDesc:
+		less = func(a, b models.Product) bool {
+			nameA, nameB := strings.ToLower(productName(a)), strings.ToLower(productName(b))
+			if desc {
+				return nameA > nameB
+			}
+			return nameA < nameB
+		}
+	}
+	if less != nil {
+		sort.SliceStable(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+	}
+
+	return matched
+}
+
+// match reports whether any variant satisfies the filter, along with the
+// lowest price among matching variants; priced is false when none of them
+// has a price.
+func (f Filter) match(product models.Product) (lowest money.Money, priced, ok bool) {
+	for _, variant := range product.Products {
+		price, hasPrice := EffectivePrice(variant)
+		if (f.MinPrice != nil || f.MaxPrice != nil) && !hasPrice {
+			continue
+		}
+		if f.MinPrice != nil && !withinBound(price, *f.MinPrice, -1) {
+			continue
+		}
+		if f.MaxPrice != nil && !withinBound(price, *f.MaxPrice, 1) {
+			continue
+		}
+		if f.InStock && !InStock(variant.Stock) {
+			continue
+		}
+		if f.OnSale && !variant.Pricing.OnSale {
+			continue
+		}
+		if !hasAttributes(variant, f.Attributes) {
+			continue
+		}
+		ok = true
+		if !hasPrice {
+			continue
+		}
+		if cmp, err := price.Cmp(lowest); !priced || (err == nil && cmp < 0) {
+			lowest, priced = price, true
+		}
+	}
+	return lowest, priced, ok
+}
+
+// withinBound reports whether price is not beyond bound on the side given
+// by beyond: -1 rejects prices below bound, +1 prices above it.
+func withinBound(price money.Money, bound float64, beyond int) bool {
+	limit, ok := boundIn(bound, price.Currency())
+	if !ok {
+		return false
+	}
+	cmp, err := price.Cmp(limit)
+	return err == nil && cmp != beyond
+}
+
+// boundIn converts a currency-less price bound, such as a query parameter or
+// facet edge, to currency, rounded half to even to its minor unit.
+func boundIn(bound float64, currency string) (money.Money, bool) {
+	rate, ok := new(big.Rat).SetString(strconv.FormatFloat(bound, 'f', -1, 64))
+	if !ok {
+		return money.Money{}, false
+	}
+	unit, err := money.Parse("1", currency)
+	if err != nil {
+		return money.Money{}, false
+	}
+	return unit.Scale(rate), true
+}
+
+// EffectivePrice returns what a shopper pays for variant: the sale price when
+// on sale, the base price otherwise.
+func EffectivePrice(variant models.ProductVariant) (money.Money, bool) {
+	amount := variant.Pricing.BasePrice
+	if variant.Pricing.OnSale && variant.Pricing.SalePrice != nil {
+		amount = variant.Pricing.SalePrice
+	}
+	if amount == nil {
+		return money.Money{}, false
+	}
+	price, err := money.FromModel(*amount)
+	if err != nil {
+		return money.Money{}, false
+	}
+	return price, true
+}
+
+// InStock reports whether a variant can currently be purchased.
+func InStock(stock models.ProductStock) bool {
+	if stock.Unlimited || stock.AllowBackorder || !stock.TrackInventory {
+		return true
+	}
+	return stock.Quantity != nil && *stock.Quantity > 0
+}
+
+// variantAttributes merges a variant's attributes and options, keyed by
+// lower-cased name.
+func variantAttributes(variant models.ProductVariant) map[string]string {
+	attributes := make(map[string]string, len(variant.Attributes)+len(variant.Variants))
+	for _, attribute := range variant.Attributes {
+		attributes[strings.ToLower(attribute.Name)] = attribute.Value
+	}
+	for _, option := range variant.Variants {
+		attributes[strings.ToLower(option.Name)] = option.Option
+	}
+	return attributes
+}
+
+func hasAttributes(variant models.ProductVariant, want map[string]string) bool {
+	if len(want) == 0 {
+		return true
+	}
+	attributes := variantAttributes(variant)
+	for name, value := range want {
+		if !strings.EqualFold(attributes[strings.ToLower(name)], value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+func variant(price string) models.ProductVariant {
+	if price == "" {
+		return models.ProductVariant{}
+	}
+	return models.ProductVariant{Pricing: models.ProductPricing{BasePrice: &models.Money{Value: price, Currency: "USD"}}}
+}
+
+func product(id string, prices ...string) models.Product {
+	p := models.Product{ID: id}
+	for _, price := range prices {
+		p.Products = append(p.Products, variant(price))
+	}
+	return p
+}
+
+func TestFilterSortsByLowestPricedVariant(t *testing.T) {
+	products := []models.Product{
+		product("unpriced-first", "", "30.00", "12.50"),
+		product("cheap", "10.00"),
+		product("no-price", ""),
+		product("dear", "99.99", "45.00"),
+	}
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{SortPriceAsc, []string{"cheap", "unpriced-first", "dear", "no-price"}},
+		{SortPriceDesc, []string{"dear", "unpriced-first", "cheap", "no-price"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			var ids []string
+			for _, p := range (Filter{Sort: tt.sort}).Apply(products) {
+				ids = append(ids, p.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("Apply() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterPriceBounds(t *testing.T) {
+	products := []models.Product{
+		product("a", "9.99"),
+		product("b", "10.00"),
+		product("c", "19.99", ""),
+		product("d", "20.01"),
+	}
+	bound := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		min, max *float64
+		want     []string
+	}{
+		{"inclusive min", bound(10), nil, []string{"b", "c", "d"}},
+		{"inclusive max", nil, bound(19.99), []string{"a", "b", "c"}},
+		{"range", bound(10), bound(20), []string{"b", "c"}},
+		{"sub-cent bound rounds half to even", bound(10.005), nil, []string{"b", "c", "d"}},
+		{"empty range", bound(20.02), bound(30), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for _, p := range (Filter{MinPrice: tt.min, MaxPrice: tt.max}).Apply(products) {
+				ids = append(ids, p.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("Apply() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
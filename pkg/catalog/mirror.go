@@ -0,0 +1,171 @@
+// Package catalog keeps a local mirror of the Squarespace product catalog so
+// the storefront can keep serving products when the upstream is unavailable.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	productsBucket = []byte("products")
+	metaBucket     = []byte("meta")
+	stateKey       = []byte("state")
+)
+
+// SyncState records the progress of the last successful sync.
+type SyncState struct {
+	LastSync     time.Time `json:"lastSync"`
+	LastFullSync time.Time `json:"lastFullSync"`
+	// HighWaterMark is the largest SystemData.ModifiedOn seen, in Unix milliseconds.
+	HighWaterMark int64 `json:"highWaterMark"`
+	Products      int   `json:"products"`
+}
+
+// Mirror is an embedded, file-backed product store.
+type Mirror struct {
+	db *bolt.DB
+}
+
+func Open(path string) (*Mirror, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open catalog mirror: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{productsBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize catalog mirror: %w", err)
+	}
+
+	return &Mirror{db: db}, nil
+}
+
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+func (m *Mirror) Product(productID string) (*models.Product, bool, error) {
+	var product *models.Product
+	err := m.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(productsBucket).Get([]byte(productID))
+		if data == nil {
+			return nil
+		}
+		product = &models.Product{}
+		return json.Unmarshal(data, product)
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read product %s from mirror: %w", productID, err)
+	}
+	return product, product != nil, nil
+}
+
+// Products returns every mirrored product, ordered by ID.
+func (m *Mirror) Products() ([]models.Product, error) {
+	var products []models.Product
+	err := m.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(productsBucket).ForEach(func(_, data []byte) error {
+			var product models.Product
+			if err := json.Unmarshal(data, &product); err != nil {
+				return err
+			}
+			products = append(products, product)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read products from mirror: %w", err)
+	}
+	return products, nil
+}
+
+func (m *Mirror) State() (SyncState, error) {
+	var state SyncState
+	err := m.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(metaBucket).Get(stateKey)
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, &state)
+	})
+	if err != nil {
+		return SyncState{}, fmt.Errorf("failed to read mirror state: %w", err)
+	}
+	return state, nil
+}
+
+// apply stores products and the updated sync state in one transaction. A full
+// sync also removes products that are no longer upstream.
+func (m *Mirror) apply(products []models.Product, full bool, now time.Time) (SyncState, error) {
+	var state SyncState
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(productsBucket)
+		meta := tx.Bucket(metaBucket)
+
+		if data := meta.Get(stateKey); data != nil {
+			if err := json.Unmarshal(data, &state); err != nil {
+				return err
+			}
+		}
+
+		seen := make(map[string]bool, len(products))
+		for _, product := range products {
+			data, err := json.Marshal(product)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(product.ID), data); err != nil {
+				return err
+			}
+			seen[product.ID] = true
+			if product.SystemData.ModifiedOn > state.HighWaterMark {
+				state.HighWaterMark = product.SystemData.ModifiedOn
+			}
+		}
+
+		if full {
+			var stale [][]byte
+			bucket.ForEach(func(key, _ []byte) error {
+				if !seen[string(key)] {
+					stale = append(stale, append([]byte(nil), key...))
+				}
+				return nil
+			})
+			for _, key := range stale {
+				if err := bucket.Delete(key); err != nil {
+					return err
+				}
+			}
+			state.LastFullSync = now
+		}
+
+		state.LastSync = now
+		state.Products = 0
+		bucket.ForEach(func(_, _ []byte) error {
+			state.Products++
+			return nil
+		})
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		return meta.Put(stateKey, data)
+	})
+	if err != nil {
+		return SyncState{}, fmt.Errorf("failed to write catalog mirror: %w", err)
+	}
+	return state, nil
+}
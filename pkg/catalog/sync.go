@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+// Syncer pulls products from Squarespace into a Mirror. Incremental syncs
+// only fetch products modified since the last high-water mark; periodic full
+// syncs also drop products deleted upstream.
+type Syncer struct {
+	mirror       *Mirror
+	api          squarespace.API
+	interval     time.Duration
+	fullInterval time.Duration
+}
+
+func NewSyncer(mirror *Mirror, api squarespace.API, interval, fullInterval time.Duration) *Syncer {
+	return &Syncer{
+		mirror:       mirror,
+		api:          api,
+		interval:     interval,
+		fullInterval: fullInterval,
+	}
+}
+
+// Sync runs one sync pass. A full pass is forced when the mirror has never
+// completed one.
+func (s *Syncer) Sync(ctx context.Context, full bool) (SyncState, error) {
+	state, err := s.mirror.State()
+	if err != nil {
+		return SyncState{}, err
+	}
+	if state.LastFullSync.IsZero() {
+		full = true
+	}
+
+	var options []squarespace.ProductOption
+	if !full && state.HighWaterMark > 0 {
+		options = append(options, squarespace.WithProductModifiedAfter(time.UnixMilli(state.HighWaterMark)))
+	}
+
+	var products []models.Product
+	it := squarespace.NewProductIterator(ctx, s.api, options...)
+	for it.Next() {
+		products = append(products, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return state, err
+	}
+
+	return s.mirror.apply(products, full, time.Now())
+}
+
+// Run syncs immediately and then every interval until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		state, err := s.mirror.State()
+		full := err == nil && s.fullInterval > 0 && time.Since(state.LastFullSync) >= s.fullInterval
+		if state, err := s.Sync(ctx, full); err != nil {
+			log.Printf("catalog: sync failed: %v", err)
+		} else {
+			log.Printf("catalog: synced, %d products mirrored", state.Products)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package checkout converts a cart into a Squarespace order, pricing it
+// from the catalog rather than trusting the client.
+package checkout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/cart"
+	"github.com/birddigital/store.adrienbird.net/pkg/idempotency"
+	"github.com/birddigital/store.adrienbird.net/pkg/inventory"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/money"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/validation"
+)
+
+var (
+	ErrEmptyCart          = errors.New("cart is empty")
+	ErrCheckoutInProgress = errors.New("cart is already being checked out")
+)
+
+// PriceChangedError is returned when catalog prices moved since items were
+// added. The cart has been updated with current prices, so the shopper can
+// review and check out again.
+type PriceChangedError struct {
+	Changes []cart.PriceChange
+}
+
+func (e *PriceChangedError) Error() string {
+	return fmt.Sprintf("prices changed for %d cart item(s)", len(e.Changes))
+}
+
+type Request struct {
+	CartToken       string          `json:"cartToken" binding:"required"`
+	Email           string          `json:"email" binding:"required"`
+	BillingAddress  models.Address  `json:"billingAddress"`
+	ShippingAddress *models.Address `json:"shippingAddress,omitempty"`
+}
+
+type Service struct {
+	api          *squarespace.Client
+	carts        *cart.Service
+	reservations *inventory.Reservations
+
+	mu          sync.Mutex
+	checkingOut map[string]bool
+}
+
+func NewService(api *squarespace.Client, carts *cart.Service, reservations *inventory.Reservations) *Service {
+	return &Service{
+		api:          api,
+		carts:        carts,
+		reservations: reservations,
+		checkingOut:  make(map[string]bool),
+	}
+}
+
+// Start holds stock for the cart's items while the shopper completes
+// checkout. Starting again refreshes the hold with the cart's current items.
+func (s *Service) Start(ctx context.Context, cartToken string) (*inventory.Reservation, error) {
+	current, err := s.carts.Get(ctx, cartToken)
+	if err != nil {
+		return nil, err
+	}
+	if len(current.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	return s.reservations.Reserve(ctx, current.ID, holds(current))
+}
+
+// Cancel releases the cart's stock hold, if it has one.
+func (s *Service) Cancel(ctx context.Context, cartToken string) error {
+	current, err := s.carts.Get(ctx, cartToken)
+	if err != nil {
+		return err
+	}
+	s.reservations.Release(current.ID)
+	return nil
+}
+
+// Checkout re-prices the cart, holds its stock, creates the order upstream,
+// deducts the held stock and discards the cart. A second checkout of a cart
+// already checking out, e.g. from a double-click, fails with
+// ErrCheckoutInProgress instead of placing another order.
+func (s *Service) Checkout(ctx context.Context, req Request) (*models.Order, error) {
+	// Reject bad contact details before touching the catalog
+	errs := validation.ValidateEmail(req.Email)
+	errs = append(errs, validation.ValidateAddress("billingAddress", req.BillingAddress)...)
+	if req.ShippingAddress != nil {
+		errs = append(errs, validation.ValidateAddress("shippingAddress", *req.ShippingAddress)...)
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	current, err := s.carts.Get(ctx, req.CartToken)
+	if err != nil {
+		return nil, err
+	}
+	done, err := s.begin(current.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	priced, changes, err := s.carts.Reprice(ctx, req.CartToken)
+	if err != nil {
+		return nil, err
+	}
+	if len(priced.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	if len(changes) > 0 {
+		return nil, &PriceChangedError{Changes: changes}
+	}
+
+	order, err := BuildOrder(req, priced)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateOrder(order).Err(); err != nil {
+		return nil, err
+	}
+	errs, err = validation.ValidateOrderCustomizations(ctx, order.LineItems, s.api.GetProduct)
+	if err != nil {
+		return nil, err
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	// Confirm the hold from Start, or place one now, so stock taken by other
+	// checkouts can't be oversold
+	if _, err := s.reservations.Reserve(ctx, priced.ID, holds(priced)); err != nil {
+		return nil, err
+	}
+
+	// Only the order creation carries the client's idempotency key upstream
+	created, err := s.api.CreateOrder(squarespace.WithIdempotencyKey(ctx, idempotency.KeyFromContext(ctx)), order)
+	if err != nil {
+		s.reservations.Release(priced.ID)
+		return nil, err
+	}
+
+	// The order exists, so a failed deduction is logged for follow-up rather
+	// than failing the checkout
+	if err := s.reservations.Commit(context.WithoutCancel(ctx), priced.ID, "order "+created.ID); err != nil {
+		log.Printf("checkout: failed to deduct stock for order %s: %v", created.ID, err)
+	}
+
+	if err := s.carts.Delete(ctx, req.CartToken); err != nil {
+		log.Printf("checkout: failed to delete cart after order %s: %v", created.ID, err)
+	}
+	return created, nil
+}
+
+// begin marks cart id as checking out, returning the function that clears
+// the mark.
+func (s *Service) begin(id string) (done func(), err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.checkingOut[id] {
+		return nil, ErrCheckoutInProgress
+	}
+	s.checkingOut[id] = true
+	return func() {
+		s.mu.Lock()
+		delete(s.checkingOut, id)
+		s.mu.Unlock()
+	}, nil
+}
+
+// BuildOrder assembles the order for a priced cart. Tax, shipping and
+// discounts are left at zero for Squarespace to apply.
+func BuildOrder(req Request, priced *cart.Cart) (*models.Order, error) {
+	zeroAmount, err := money.New(0, priced.Currency)
+	if err != nil {
+		return nil, err
+	}
+	zero := zeroAmount.Model()
+
+	order := &models.Order{
+		Email:           req.Email,
+		BillingAddress:  req.BillingAddress,
+		ShippingAddress: req.ShippingAddress,
+		Totals: models.OrderTotals{
+			Subtotal: priced.Subtotal,
+			Tax:      zero,
+			Shipping: zero,
+			Discount: zero,
+			Total:    priced.Subtotal,
+		},
+	}
+	for _, item := range priced.Items {
+		order.LineItems = append(order.LineItems, models.OrderLineItem{
+			ProductID:      item.ProductID,
+			VariantID:      item.VariantID,
+			SKU:            item.SKU,
+			ProductName:    item.Name,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			TotalPrice:     item.TotalPrice,
+			Customizations: item.Customizations,
+		})
+	}
+	return order, nil
+}
+
+func holds(c *cart.Cart) []inventory.Hold {
+	holds := make([]inventory.Hold, len(c.Items))
+	for i, item := range c.Items {
+		holds[i] = inventory.Hold{VariantID: item.VariantID, Quantity: item.Quantity}
+	}
+	return holds
+}
@@ -0,0 +1,293 @@
+package checkout
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/cart"
+	"github.com/birddigital/store.adrienbird.net/pkg/inventory"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+type fixture struct {
+	server       *sstest.Server
+	client       *squarespace.Client
+	carts        *cart.Service
+	reservations *inventory.Reservations
+	checkout     *Service
+}
+
+// newFixture wires a checkout service to a seeded sstest fake. Extra
+// transports wrap the client's, e.g. to fail order creation.
+func newFixture(t *testing.T, transport http.RoundTripper) *fixture {
+	t.Helper()
+	server := sstest.NewServer()
+	t.Cleanup(server.Close)
+	server.SeedDemoData()
+
+	var options []squarespace.ClientOption
+	if transport != nil {
+		options = append(options, squarespace.WithTransport(transport))
+	}
+	client := squarespace.NewClient(server.Config(), options...)
+	carts := cart.NewService(client, cart.NewMemoryStore(), []byte("test-secret"), time.Hour)
+	reservations := inventory.NewReservations(client, inventory.NewService(client, inventory.NewMemoryAuditLog(100)), time.Hour)
+	return &fixture{
+		server:       server,
+		client:       client,
+		carts:        carts,
+		reservations: reservations,
+		checkout:     NewService(client, carts, reservations),
+	}
+}
+
+// cartWith creates a cart holding quantity of a variant.
+func (f *fixture) cartWith(t *testing.T, productID, variantID string, quantity int) *cart.Cart {
+	t.Helper()
+	ctx := context.Background()
+	c, err := f.carts.Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = f.carts.AddItem(ctx, c.Token, cart.AddItemRequest{ProductID: productID, VariantID: variantID, Quantity: quantity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func (f *fixture) stock(t *testing.T, variantID string) int {
+	t.Helper()
+	stock, err := f.client.GetInventory(context.Background(), variantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *stock.Quantity
+}
+
+func request(token string) Request {
+	state := "OR"
+	return Request{
+		CartToken: token,
+		Email:     "grace@example.com",
+		BillingAddress: models.Address{
+			FirstName:    "Grace",
+			LastName:     "Hopper",
+			AddressLine1: "1 Compiler Court",
+			City:         "Portland",
+			State:        &state,
+			PostalCode:   "97201",
+			Country:      "US",
+		},
+	}
+}
+
+func TestCheckoutPlacesOrderAndDeductsStock(t *testing.T) {
+	f := newFixture(t, nil)
+	ctx := context.Background()
+	c := f.cartWith(t, "prod-print", "var-print-a3", 2)
+
+	if _, err := f.checkout.Start(ctx, c.Token); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	order, err := f.checkout.Checkout(ctx, request(c.Token))
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+
+	if order.ID != "order-1001" || order.Totals.Total.Value != "70.00" {
+		t.Errorf("order = %s totalling %s, want order-1001 totalling 70.00", order.ID, order.Totals.Total.Value)
+	}
+	if got := f.stock(t, "var-print-a3"); got != 10 {
+		t.Errorf("stock = %d, want 10", got)
+	}
+	if _, err := f.reservations.Get(c.ID); !errors.Is(err, inventory.ErrReservationNotFound) {
+		t.Errorf("reservation after checkout error = %v, want it committed", err)
+	}
+	if _, err := f.carts.Get(ctx, c.Token); !errors.Is(err, cart.ErrNotFound) {
+		t.Errorf("cart after checkout error = %v, want it deleted", err)
+	}
+}
+
+func TestCheckoutZeroDecimalCurrency(t *testing.T) {
+	f := newFixture(t, nil)
+	quantity := 5
+	f.server.AddProduct(models.Product{
+		ID:   "prod-tenugui",
+		Type: "PHYSICAL",
+		Products: []models.ProductVariant{{
+			ID:      "var-tenugui",
+			SKU:     "TENUGUI-01",
+			Name:    "Tenugui",
+			Pricing: models.ProductPricing{BasePrice: &models.Money{Value: "1500", Currency: "JPY"}},
+			Stock:   models.ProductStock{TrackInventory: true, Quantity: &quantity},
+		}},
+	})
+	c := f.cartWith(t, "prod-tenugui", "var-tenugui", 2)
+
+	order, err := f.checkout.Checkout(context.Background(), request(c.Token))
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	totals := order.Totals
+	if totals.Total != (models.Money{Value: "3000", Currency: "JPY"}) || totals.Tax != (models.Money{Value: "0", Currency: "JPY"}) {
+		t.Errorf("totals = %+v, want 3000 JPY with zero tax in whole yen", totals)
+	}
+}
+
+func TestCheckoutRejectsChangedPrices(t *testing.T) {
+	f := newFixture(t, nil)
+	ctx := context.Background()
+	c := f.cartWith(t, "prod-ebook", "var-ebook", 1)
+
+	for _, product := range sstest.DemoProducts(0) {
+		if product.ID == "prod-ebook" {
+			product.Products[0].Pricing.BasePrice = &models.Money{Value: "15.00", Currency: "USD"}
+			f.server.AddProduct(product)
+		}
+	}
+
+	_, err := f.checkout.Checkout(ctx, request(c.Token))
+	var priceErr *PriceChangedError
+	if !errors.As(err, &priceErr) {
+		t.Fatalf("Checkout() error = %v, want a PriceChangedError", err)
+	}
+	if len(priceErr.Changes) != 1 || priceErr.Changes[0].Current.Value != "15.00" {
+		t.Errorf("changes = %+v, want the ebook now at 15.00", priceErr.Changes)
+	}
+	if got := len(f.server.Orders()); got != 1 {
+		t.Errorf("fake holds %d orders, want no new one", got)
+	}
+
+	// The cart now carries the new price, so checking out again succeeds
+	if _, err := f.checkout.Checkout(ctx, request(c.Token)); err != nil {
+		t.Fatalf("second Checkout() error = %v", err)
+	}
+}
+
+func TestCheckoutRejectsUnavailableStock(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(*fixture)
+	}{
+		{"sold out upstream", func(f *fixture) {
+			quantity := 0
+			f.server.SetInventory("var-print-a2", models.ProductStock{TrackInventory: true, Quantity: &quantity})
+		}},
+		{"held by another cart", func(f *fixture) {
+			if _, err := f.reservations.Reserve(context.Background(), "other-cart", []inventory.Hold{{VariantID: "var-print-a2", Quantity: 2}}); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, nil)
+			c := f.cartWith(t, "prod-print", "var-print-a2", 1)
+			tt.prepare(f)
+
+			_, err := f.checkout.Checkout(context.Background(), request(c.Token))
+			var availability *cart.AvailabilityError
+			if !errors.As(err, &availability) {
+				t.Fatalf("Checkout() error = %v, want an AvailabilityError", err)
+			}
+			if len(availability.Items) != 1 || availability.Items[0].VariantID != "var-print-a2" {
+				t.Errorf("unavailable = %+v, want var-print-a2", availability.Items)
+			}
+			if got := len(f.server.Orders()); got != 1 {
+				t.Errorf("fake holds %d orders, want no new one", got)
+			}
+			if _, err := f.reservations.Get(c.ID); !errors.Is(err, inventory.ErrReservationNotFound) {
+				t.Errorf("reservation error = %v, want no hold placed", err)
+			}
+		})
+	}
+}
+
+func TestCheckoutReleasesHoldWhenOrderFails(t *testing.T) {
+	f := newFixture(t, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/commerce/orders") {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"type":"SERVICE_UNAVAILABLE","message":"try later"}`)),
+				Request:    req,
+			}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	}))
+	ctx := context.Background()
+	c := f.cartWith(t, "prod-print", "var-print-a2", 2)
+
+	if _, err := f.checkout.Checkout(ctx, request(c.Token)); err == nil {
+		t.Fatal("Checkout() error = nil, want the upstream failure")
+	}
+	if _, err := f.reservations.Get(c.ID); !errors.Is(err, inventory.ErrReservationNotFound) {
+		t.Errorf("reservation error = %v, want the hold released", err)
+	}
+	if got := f.stock(t, "var-print-a2"); got != 2 {
+		t.Errorf("stock = %d, want it untouched", got)
+	}
+	if _, err := f.carts.Get(ctx, c.Token); err != nil {
+		t.Errorf("cart after failed checkout error = %v, want it kept", err)
+	}
+
+	// Another shopper can take the released stock
+	other := f.cartWith(t, "prod-print", "var-print-a2", 2)
+	if _, err := f.reservations.Reserve(ctx, other.ID, []inventory.Hold{{VariantID: "var-print-a2", Quantity: 2}}); err != nil {
+		t.Errorf("Reserve() after release error = %v", err)
+	}
+}
+
+func TestConcurrentCheckoutsOfOneCartPlaceOneOrder(t *testing.T) {
+	f := newFixture(t, nil)
+	c := f.cartWith(t, "prod-print", "var-print-a3", 1)
+	f.server.SetLatency(20 * time.Millisecond)
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = f.checkout.Checkout(context.Background(), request(c.Token))
+		}(i)
+	}
+	wg.Wait()
+
+	var placed, refused int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			placed++
+		case errors.Is(err, ErrCheckoutInProgress):
+			refused++
+		default:
+			t.Errorf("Checkout() error = %v", err)
+		}
+	}
+	if placed != 1 || refused != 1 {
+		t.Fatalf("placed %d and refused %d checkouts, want one of each", placed, refused)
+	}
+	if got := len(f.server.Orders()); got != 2 {
+		t.Errorf("fake holds %d orders, want one new one", got)
+	}
+	if got := f.stock(t, "var-print-a3"); got != 11 {
+		t.Errorf("stock = %d, want 11", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/cart"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+type CartHandler struct {
+	carts *cart.Service
+}
+
+func NewCartHandler(carts *cart.Service) *CartHandler {
+	return &CartHandler{carts: carts}
+}
+
+func (h *CartHandler) CreateCart(c *gin.Context) {
+	created, err := h.carts.Create(c.Request.Context())
+	if err != nil {
+		respondCartError(c, err, "Failed to create cart")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{Data: created})
+}
+
+func (h *CartHandler) GetCart(c *gin.Context) {
+	found, err := h.carts.Get(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondCartError(c, err, "Failed to fetch cart")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: found})
+}
+
+func (h *CartHandler) AddItem(c *gin.Context) {
+	var req cart.AddItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_request",
+				Message: "Invalid cart item: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	updated, err := h.carts.AddItem(c.Request.Context(), c.Param("token"), req)
+	if err != nil {
+		respondCartError(c, err, "Failed to add item")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: updated})
+}
+
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	var req struct {
+		Quantity int `json:"quantity" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_request",
+				Message: "Invalid cart item update: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	updated, err := h.carts.UpdateItem(c.Request.Context(), c.Param("token"), c.Param("itemId"), req.Quantity)
+	if err != nil {
+		respondCartError(c, err, "Failed to update item")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: updated})
+}
+
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	updated, err := h.carts.RemoveItem(c.Request.Context(), c.Param("token"), c.Param("itemId"))
+	if err != nil {
+		respondCartError(c, err, "Failed to remove item")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: updated})
+}
+
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	updated, err := h.carts.Clear(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondCartError(c, err, "Failed to clear cart")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: updated})
+}
+
+// respondCartError maps cart errors to responses, deferring to respondError
+// for upstream failures.
+func respondCartError(c *gin.Context, err error, message string) {
+	status := 0
+	apiErr := &models.APIError{Message: err.Error()}
+
+	var stockErr *cart.StockError
+	switch {
+	case errors.Is(err, cart.ErrInvalidToken), errors.Is(err, cart.ErrNotFound):
+		status, apiErr.Type = http.StatusNotFound, "cart_not_found"
+		apiErr.Message = "Cart not found or expired"
+	case errors.Is(err, cart.ErrItemNotFound):
+		status, apiErr.Type = http.StatusNotFound, "item_not_found"
+	case errors.Is(err, cart.ErrInvalidQuantity):
+		status, apiErr.Type = http.StatusBadRequest, "validation_error"
+	case errors.Is(err, cart.ErrVariantNotFound),
+		errors.Is(err, cart.ErrNotPurchasable),
+		errors.Is(err, cart.ErrCurrencyMismatch):
+		status, apiErr.Type = http.StatusUnprocessableEntity, "invalid_item"
+	case errors.As(err, &stockErr):
+		status, apiErr.Type = http.StatusConflict, "insufficient_stock"
+		apiErr.Details = gin.H{
+			"variantId": stockErr.VariantID,
+			"requested": stockErr.Requested,
+			"available": stockErr.Available,
+		}
+	}
+
+	if status == 0 {
+		respondError(c, err, message)
+		return
+	}
+	c.JSON(status, models.APIResponse{Error: apiErr})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/cart"
+	"github.com/birddigital/store.adrienbird.net/pkg/checkout"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type CheckoutHandler struct {
+	checkout *checkout.Service
+}
+
+func NewCheckoutHandler(service *checkout.Service) *CheckoutHandler {
+	return &CheckoutHandler{checkout: service}
+}
+
+func (h *CheckoutHandler) Checkout(c *gin.Context) {
+	var req checkout.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_request",
+				Message: "Invalid checkout data: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	order, err := h.checkout.Checkout(c.Request.Context(), req)
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{Data: order})
+}
+
+func (h *CheckoutHandler) StartCheckout(c *gin.Context) {
+	reservation, err := h.checkout.Start(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: reservation})
+}
+
+func (h *CheckoutHandler) CancelCheckout(c *gin.Context) {
+	if err := h.checkout.Cancel(c.Request.Context(), c.Param("token")); err != nil {
+		respondCheckoutError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondCheckoutError(c *gin.Context, err error) {
+	var priceErr *checkout.PriceChangedError
+	var availabilityErr *cart.AvailabilityError
+	var validationErrs validation.Errors
+	switch {
+	case errors.As(err, &validationErrs):
+		respondValidationError(c, validationErrs)
+	case errors.Is(err, checkout.ErrEmptyCart):
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "empty_cart",
+				Message: "Cart has no items",
+			},
+		})
+	case errors.Is(err, checkout.ErrCheckoutInProgress):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "checkout_in_progress",
+				Message: "This cart is already being checked out",
+			},
+		})
+	case errors.As(err, &priceErr):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "price_changed",
+				Message: "Prices changed since items were added; review the cart and retry",
+				Details: priceErr.Changes,
+			},
+		})
+	case errors.As(err, &availabilityErr):
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "insufficient_stock",
+				Message: "Some items are no longer available in the requested quantity",
+				Details: availabilityErr.Items,
+			},
+		})
+	default:
+		respondCartError(c, err, "Failed to check out")
+	}
+}
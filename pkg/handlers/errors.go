@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest is the de facto status for requests abandoned by the client.
+const statusClientClosedRequest = 499
+
+// respondError maps an error from the Squarespace client onto an HTTP status
+// and APIError. message describes the failed operation, e.g. "Failed to fetch
+// orders"; err itself is only logged, since it can carry upstream or internal
+// detail that clients shouldn't see.
+func respondError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	apiErr := &models.APIError{
+		Type:    "internal_error",
+		Message: message,
+	}
+
+	var upstream *squarespace.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		c.AbortWithStatus(statusClientClosedRequest)
+		return
+	case errors.Is(err, squarespace.ErrCircuitOpen):
+		status = http.StatusServiceUnavailable
+		apiErr.Type = "upstream_unavailable"
+		apiErr.Message = "Squarespace is temporarily unavailable, please retry shortly"
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+		apiErr.Type = "upstream_timeout"
+	case errors.Is(err, squarespace.ErrNotFound):
+		status = http.StatusNotFound
+		apiErr.Type = "not_found"
+	case errors.Is(err, squarespace.ErrUnauthorized):
+		// Our credentials were rejected; that's not the caller's fault
+		status = http.StatusBadGateway
+		apiErr.Type = "upstream_auth_error"
+	case errors.Is(err, squarespace.ErrRateLimited):
+		status = http.StatusTooManyRequests
+		apiErr.Type = "rate_limited"
+	case errors.As(err, &upstream):
+		if upstream.StatusCode >= 400 && upstream.StatusCode < 500 {
+			status = http.StatusBadRequest
+			apiErr.Type = "upstream_rejected"
+		} else {
+			status = http.StatusBadGateway
+			apiErr.Type = "upstream_error"
+		}
+	}
+
+	if errors.As(err, &upstream) && upstream.RequestID != "" {
+		apiErr.Details = gin.H{"requestId": upstream.RequestID}
+	}
+	if status >= http.StatusInternalServerError {
+		log.Printf("%s %s: %s: %v", c.Request.Method, c.FullPath(), message, err)
+	}
+
+	c.JSON(status, models.APIResponse{Error: apiErr})
+}
+
+// respondValidationError answers 400 with every field error in Details.
+func respondValidationError(c *gin.Context, errs validation.Errors) {
+	c.JSON(http.StatusBadRequest, models.APIResponse{
+		Error: &models.APIError{
+			Type:    "validation_error",
+			Message: "Order validation failed",
+			Details: errs,
+		},
+	})
+}
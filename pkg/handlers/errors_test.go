@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondErrorHidesInternalDetail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/orders", nil)
+
+	respondError(c, errors.New("dial tcp 10.0.0.12:6379: connection refused"), "Failed to fetch orders")
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "10.0.0.12") {
+		t.Errorf("response leaks the error: %s", rec.Body)
+	}
+	var response apiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Error == nil || response.Error.Type != "internal_error" || response.Error.Message != "Failed to fetch orders" {
+		t.Errorf("error = %+v, want internal_error with the generic message", response.Error)
+	}
+}
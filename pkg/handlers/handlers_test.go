@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/internal/config"
+	"github.com/birddigital/store.adrienbird.net/pkg/cache"
+	"github.com/birddigital/store.adrienbird.net/pkg/idempotency"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter wires the product and order handlers to a seeded sstest fake.
+func newTestRouter(t *testing.T) (*sstest.Server, *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := sstest.NewServer()
+	t.Cleanup(server.Close)
+	server.SeedDemoData()
+
+	cfg := &config.Config{}
+	client := squarespace.NewClient(server.Config())
+	productHandler := NewProductHandler(cfg, client)
+	orderHandler := NewOrderHandler(cfg, client)
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	api.GET("/products", productHandler.GetProducts)
+	api.GET("/products/:id", productHandler.GetProduct)
+	api.GET("/orders", orderHandler.GetOrders)
+	api.GET("/orders/:id", orderHandler.GetOrder)
+	api.POST("/orders", orderHandler.CreateOrder)
+	return server, router
+}
+
+// serve runs one request through router and decodes the APIResponse envelope.
+func serve(t *testing.T, router *gin.Engine, method, path string, body interface{}) (*httptest.ResponseRecorder, apiResponse) {
+	t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var response apiResponse
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec, response
+}
+
+type apiResponse struct {
+	Data       json.RawMessage    `json:"data"`
+	Error      *models.APIError   `json:"error"`
+	Pagination *models.Pagination `json:"pagination"`
+}
+
+func TestGetProductsPaginates(t *testing.T) {
+	_, router := newTestRouter(t)
+
+	rec, first := serve(t, router, http.MethodGet, "/api/v1/products?limit=2", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var products []models.Product
+	json.Unmarshal(first.Data, &products)
+	if len(products) != 2 || first.Pagination == nil || first.Pagination.NextPage == nil {
+		t.Fatalf("first page = %d products, pagination %+v; want 2 and a cursor", len(products), first.Pagination)
+	}
+
+	rec, second := serve(t, router, http.MethodGet, "/api/v1/products?limit=2&cursor="+*first.Pagination.NextPage, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	json.Unmarshal(second.Data, &products)
+	if len(products) != 1 || second.Pagination.NextPage != nil {
+		t.Errorf("second page = %d products, next %v; want 1 and no cursor", len(products), second.Pagination.NextPage)
+	}
+}
+
+func TestBrowseProductsHugeLimit(t *testing.T) {
+	_, router := newTestRouter(t)
+
+	rec, response := serve(t, router, http.MethodGet, "/api/v1/products?facets=true&offset=1&limit=9223372036854775807", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var products []models.Product
+	json.Unmarshal(response.Data, &products)
+	if len(products) != 2 {
+		t.Errorf("page = %d products, want the 2 after the offset", len(products))
+	}
+}
+
+func TestHandlersMapUpstreamErrors(t *testing.T) {
+	server, router := newTestRouter(t)
+
+	tests := []struct {
+		name      string
+		fault     *sstest.Fault
+		path      string
+		status    int
+		errorType string
+	}{
+		{"invalid limit", nil, "/api/v1/orders?limit=abc", http.StatusBadRequest, "invalid_parameter"},
+		{"negative product limit", nil, "/api/v1/products?limit=-1", http.StatusBadRequest, "invalid_parameter"},
+		{"negative product offset", nil, "/api/v1/products?offset=-5", http.StatusBadRequest, "invalid_parameter"},
+		{"negative browse offset", nil, "/api/v1/products?inStock=true&offset=-5", http.StatusBadRequest, "invalid_parameter"},
+		{"unknown order", nil, "/api/v1/orders/order-404", http.StatusNotFound, "not_found"},
+		{"unknown product", nil, "/api/v1/products/prod-404", http.StatusNotFound, "not_found"},
+		{"rate limited", &sstest.Fault{Status: http.StatusTooManyRequests, Count: 1}, "/api/v1/orders", http.StatusTooManyRequests, "rate_limited"},
+		{"upstream outage", &sstest.Fault{Status: http.StatusServiceUnavailable, Count: 1}, "/api/v1/orders/order-1000", http.StatusBadGateway, "upstream_error"},
+		{"upstream rejects", &sstest.Fault{Status: http.StatusUnprocessableEntity, Count: 1}, "/api/v1/products", http.StatusBadRequest, "upstream_rejected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.ClearFault()
+			if tt.fault != nil {
+				server.InjectFault(*tt.fault)
+			}
+			rec, response := serve(t, router, http.MethodGet, tt.path, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if response.Error == nil || response.Error.Type != tt.errorType {
+				t.Errorf("error = %+v, want type %s", response.Error, tt.errorType)
+			}
+		})
+	}
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	server, router := newTestRouter(t)
+
+	rec, existing := serve(t, router, http.MethodGet, "/api/v1/orders/order-1000", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET order status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var order models.Order
+	if err := json.Unmarshal(existing.Data, &order); err != nil {
+		t.Fatal(err)
+	}
+
+	// Place the same basket again as a new order
+	order.ID, order.OrderNumber, order.Status = "", "", ""
+	rec, created := serve(t, router, http.MethodPost, "/api/v1/orders", order)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST order status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+	var createdOrder models.Order
+	json.Unmarshal(created.Data, &createdOrder)
+	if createdOrder.ID != "order-1001" {
+		t.Errorf("created order id = %q, want order-1001", createdOrder.ID)
+	}
+	if got := len(server.Orders()); got != 2 {
+		t.Errorf("fake holds %d orders, want 2", got)
+	}
+
+	rec, list := serve(t, router, http.MethodGet, "/api/v1/orders?limit=1", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET orders status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if list.Pagination == nil || list.Pagination.TotalResults == nil || *list.Pagination.TotalResults != 2 {
+		t.Errorf("pagination = %+v, want 2 total results", list.Pagination)
+	}
+
+	// Unknown products are a validation error, not a missing route
+	unknown := order
+	unknown.LineItems = append([]models.OrderLineItem(nil), order.LineItems...)
+	unknown.LineItems[0].ProductID = "prod-404"
+	rec, rejected := serve(t, router, http.MethodPost, "/api/v1/orders", unknown)
+	if rec.Code != http.StatusBadRequest || rejected.Error == nil || rejected.Error.Type != "validation_error" {
+		t.Fatalf("unknown product = %d %+v, want 400 validation_error", rec.Code, rejected.Error)
+	}
+	if !strings.Contains(rec.Body.String(), `"field":"lineItems[0].productId","code":"unknown_product"`) {
+		t.Errorf("unknown product details = %s, want lineItems[0].productId unknown_product", rec.Body)
+	}
+
+	// Orders that fail validation never reach Squarespace
+	order.Email = "not-an-email"
+	before := server.Requests()
+	rec, rejected = serve(t, router, http.MethodPost, "/api/v1/orders", order)
+	if rec.Code != http.StatusBadRequest || rejected.Error == nil || rejected.Error.Type != "validation_error" {
+		t.Fatalf("invalid order = %d %+v, want 400 validation_error", rec.Code, rejected.Error)
+	}
+	if server.Requests() != before {
+		t.Errorf("invalid order made %d upstream requests, want 0", server.Requests()-before)
+	}
+}
+
+func TestBrowseProductsSetsCacheHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := sstest.NewServer()
+	defer server.Close()
+	server.SeedDemoData()
+
+	policy := cache.Policy{TTL: time.Minute}
+	client := squarespace.NewCachedClient(squarespace.NewClient(server.Config()), cache.New(cache.NewLRU(16)), policy, policy)
+	router := gin.New()
+	router.GET("/products", NewProductHandler(&config.Config{}, client).GetProducts)
+
+	for _, want := range []string{"MISS", "HIT"} {
+		rec, _ := serve(t, router, http.MethodGet, "/products?inStock=true&facets=true", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get("X-Cache"); got != want {
+			t.Errorf("X-Cache = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestCreateOrderForwardsIdempotencyKeyOnlyUpstreamOnCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := sstest.NewServer()
+	defer server.Close()
+	server.SeedDemoData()
+
+	var mu sync.Mutex
+	forwarded := make(map[string]string)
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		forwarded[req.Method+" "+req.URL.Path] = req.Header.Get(idempotency.HeaderKey)
+		mu.Unlock()
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	client := squarespace.NewClient(server.Config(), squarespace.WithTransport(transport))
+
+	router := gin.New()
+	router.POST("/orders", idempotency.Middleware(idempotency.NewMemoryStore(), time.Hour), NewOrderHandler(&config.Config{}, client).CreateOrder)
+
+	order := server.Orders()[0]
+	order.ID, order.OrderNumber, order.Status = "", "", ""
+	body, _ := json.Marshal(order)
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+	req.Header.Set(idempotency.HeaderKey, "client-key")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+
+	if key := forwarded["POST /1.0/commerce/orders"]; key == "" {
+		t.Error("order creation was sent without an idempotency key")
+	}
+	if key, ok := forwarded["GET /1.0/commerce/products/prod-print"]; !ok || key != "" {
+		t.Errorf("product lookup made = %v, key %q; want it made without a key", ok, key)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -12,13 +12,13 @@ import (
 
 type HealthHandler struct {
 	cfg    *config.Config
-	client *squarespace.Client
+	client squarespace.API
 }
 
-func NewHealthHandler(cfg *config.Config) *HealthHandler {
+func NewHealthHandler(cfg *config.Config, client squarespace.API) *HealthHandler {
 	return &HealthHandler{
 		cfg:    cfg,
-		client: squarespace.NewClient(&cfg.Squarespace),
+		client: client,
 	}
 }
 
@@ -36,7 +36,12 @@ func (h *HealthHandler) Health(c *gin.Context) {
 	}
 	start := time.Now()
 
-	if err := h.client.HealthCheck(); err != nil {
+	breakerState := h.client.BreakerState()
+	if breakerState == squarespace.BreakerOpen {
+		// Don't probe an upstream the breaker already considers down
+		squarespaceCheck.Status = "unhealthy"
+		squarespaceCheck.Message = "Circuit breaker open, skipping upstream check"
+	} else if err := h.client.HealthCheck(c.Request.Context()); err != nil {
 		squarespaceCheck.Status = "unhealthy"
 		squarespaceCheck.Message = err.Error()
 	} else {
@@ -44,6 +49,16 @@ func (h *HealthHandler) Health(c *gin.Context) {
 	}
 	healthResponse.Checks["squarespace_api"] = squarespaceCheck
 
+	// Report circuit breaker state
+	breakerCheck := models.Health{
+		Status:  "healthy",
+		Message: "state: " + breakerState.String(),
+	}
+	if breakerState != squarespace.BreakerClosed {
+		breakerCheck.Status = "warning"
+	}
+	healthResponse.Checks["circuit_breaker"] = breakerCheck
+
 	// Check configuration
 	configCheck := models.Health{
 		Status: "healthy",
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/admin"
+	"github.com/birddigital/store.adrienbird.net/pkg/inventory"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// maxInventoryBatch caps the number of ids in a batch inventory read.
+const maxInventoryBatch = 50
+
+type InventoryHandler struct {
+	inventory *inventory.Service
+}
+
+func NewInventoryHandler(inventory *inventory.Service) *InventoryHandler {
+	return &InventoryHandler{inventory: inventory}
+}
+
+func (h *InventoryHandler) GetInventory(c *gin.Context) {
+	level, err := h.inventory.Get(c.Request.Context(), c.Param("variantId"))
+	if err != nil {
+		respondError(c, err, "Failed to fetch inventory")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: level})
+}
+
+func (h *InventoryHandler) GetInventoryBatch(c *gin.Context) {
+	var ids []string
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "missing_parameter",
+				Message: "ids parameter is required",
+			},
+		})
+		return
+	}
+	if len(ids) > maxInventoryBatch {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_parameter",
+				Message: "At most " + strconv.Itoa(maxInventoryBatch) + " ids may be requested at once",
+			},
+		})
+		return
+	}
+
+	batch, err := h.inventory.GetMany(c.Request.Context(), ids)
+	if err != nil {
+		respondError(c, err, "Failed to fetch inventory")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: batch})
+}
+
+func (h *InventoryHandler) AdjustInventory(c *gin.Context) {
+	var req inventory.Adjustment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_request",
+				Message: "Invalid inventory adjustment: " + err.Error(),
+			},
+		})
+		return
+	}
+
+	entry, err := h.inventory.Adjust(c.Request.Context(), c.Param("variantId"), req, admin.Actor(c))
+	if err != nil {
+		respondInventoryError(c, err, "Failed to adjust inventory")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: entry})
+}
+
+func (h *InventoryHandler) GetAuditLog(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 {
+		respondInvalidParameter(c, "limit")
+		return
+	}
+
+	entries, err := h.inventory.Audit(c.Request.Context(), inventory.AuditFilter{
+		VariantID: c.Query("variantId"),
+		Actor:     c.Query("actor"),
+		Limit:     limit,
+	})
+	if err != nil {
+		respondError(c, err, "Failed to fetch inventory audit log")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Data: entries})
+}
+
+// respondInventoryError maps inventory errors to responses, deferring to
+// respondError for upstream failures.
+func respondInventoryError(c *gin.Context, err error, message string) {
+	status := 0
+	apiErr := &models.APIError{Message: err.Error()}
+
+	var stockErr *inventory.NegativeStockError
+	switch {
+	case errors.Is(err, inventory.ErrInvalidAdjustment):
+		status, apiErr.Type = http.StatusBadRequest, "validation_error"
+	case errors.Is(err, inventory.ErrNotTracked):
+		status, apiErr.Type = http.StatusConflict, "inventory_not_tracked"
+	case errors.As(err, &stockErr):
+		status, apiErr.Type = http.StatusConflict, "insufficient_stock"
+		apiErr.Details = stockErr
+	}
+
+	if status == 0 {
+		respondError(c, err, message)
+		return
+	}
+	c.JSON(status, models.APIResponse{Error: apiErr})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/admin"
+	"github.com/birddigital/store.adrienbird.net/pkg/inventory"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+	"github.com/gin-gonic/gin"
+)
+
+func newInventoryRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := sstest.NewServer()
+	t.Cleanup(server.Close)
+	server.SeedDemoData()
+
+	client := squarespace.NewClient(server.Config())
+	handler := NewInventoryHandler(inventory.NewService(client, inventory.NewMemoryAuditLog(100)))
+
+	router := gin.New()
+	router.GET("/inventory", handler.GetInventoryBatch)
+	adminAPI := router.Group("/admin", admin.Middleware(map[string]string{"ada": "ada-token"}))
+	adminAPI.POST("/inventory/:variantId/adjustments", handler.AdjustInventory)
+	adminAPI.GET("/inventory/audit", handler.GetAuditLog)
+	return router
+}
+
+// serveAdmin runs one request carrying an admin token.
+func serveAdmin(t *testing.T, router *gin.Engine, method, path, body string) (*httptest.ResponseRecorder, apiResponse) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer ada-token")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var response apiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec, response
+}
+
+func TestAdjustInventory(t *testing.T) {
+	router := newInventoryRouter(t)
+
+	tests := []struct {
+		name      string
+		variantID string
+		body      string
+		status    int
+		errorType string
+	}{
+		{"decrement", "var-print-a3", `{"operation":"decrement","quantity":2,"reason":"damaged"}`, http.StatusOK, ""},
+		{"below zero", "var-print-a2", `{"operation":"decrement","quantity":3}`, http.StatusConflict, "insufficient_stock"},
+		{"untracked", "var-ebook", `{"operation":"increment","quantity":1}`, http.StatusConflict, "inventory_not_tracked"},
+		{"invalid quantity", "var-print-a3", `{"operation":"increment","quantity":0}`, http.StatusBadRequest, "validation_error"},
+		{"missing operation", "var-print-a3", `{"quantity":1}`, http.StatusBadRequest, "invalid_request"},
+		{"unknown variant", "var-missing", `{"operation":"set","quantity":1}`, http.StatusNotFound, "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, response := serveAdmin(t, router, http.MethodPost, "/admin/inventory/"+tt.variantID+"/adjustments", tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.errorType == "" {
+				var entry inventory.Entry
+				json.Unmarshal(response.Data, &entry)
+				if entry.Actor != "ada" || entry.Quantity != 10 || entry.Reason != "damaged" {
+					t.Errorf("entry = %+v, want ada's decrement to 10", entry)
+				}
+				return
+			}
+			if response.Error == nil || response.Error.Type != tt.errorType {
+				t.Errorf("error = %+v, want type %s", response.Error, tt.errorType)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/inventory/var-print-a3/adjustments", strings.NewReader(`{"operation":"set","quantity":1}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("adjustment without a token = %d, want 401", rec.Code)
+	}
+}
+
+func TestGetInventoryBatch(t *testing.T) {
+	router := newInventoryRouter(t)
+
+	rec, response := serve(t, router, http.MethodGet, "/inventory?ids=var-print-a3,%20var-missing,,var-print-a3", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var batch inventory.Batch
+	json.Unmarshal(response.Data, &batch)
+	if len(batch.Items) != 1 || batch.Items[0].VariantID != "var-print-a3" || len(batch.NotFound) != 1 || batch.NotFound[0] != "var-missing" {
+		t.Errorf("batch = %+v, want var-print-a3 with var-missing not found", batch)
+	}
+
+	tooMany := strings.Repeat("var-print-a3,", maxInventoryBatch+1)
+	for path, errorType := range map[string]string{
+		"/inventory":                "missing_parameter",
+		"/inventory?ids=,,":         "missing_parameter",
+		"/inventory?ids=" + tooMany: "invalid_parameter",
+	} {
+		rec, response := serve(t, router, http.MethodGet, path, nil)
+		if rec.Code != http.StatusBadRequest || response.Error == nil || response.Error.Type != errorType {
+			t.Errorf("GET %.40s = %d %+v, want 400 %s", path, rec.Code, response.Error, errorType)
+		}
+	}
+}
+
+func TestGetAuditLog(t *testing.T) {
+	router := newInventoryRouter(t)
+	for _, adjustment := range []struct{ variantID, body string }{
+		{"var-print-a3", `{"operation":"increment","quantity":1}`},
+		{"var-print-a2", `{"operation":"set","quantity":5}`},
+		{"var-print-a3", `{"operation":"decrement","quantity":1}`},
+	} {
+		if rec, _ := serveAdmin(t, router, http.MethodPost, "/admin/inventory/"+adjustment.variantID+"/adjustments", adjustment.body); rec.Code != http.StatusOK {
+			t.Fatalf("adjustment status = %d: %s", rec.Code, rec.Body)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"3", "2", "1"}},
+		{"?variantId=var-print-a3", []string{"3", "1"}},
+		{"?limit=1", []string{"3"}},
+		{"?actor=grace", []string{}},
+	}
+	for _, tt := range tests {
+		rec, response := serveAdmin(t, router, http.MethodGet, "/admin/inventory/audit"+tt.query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET audit%s status = %d: %s", tt.query, rec.Code, rec.Body)
+		}
+		var entries []inventory.Entry
+		json.Unmarshal(response.Data, &entries)
+		ids := []string{}
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("GET audit%s = %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+
+	if rec, response := serveAdmin(t, router, http.MethodGet, "/admin/inventory/audit?limit=0", ""); rec.Code != http.StatusBadRequest || response.Error == nil {
+		t.Errorf("limit=0 = %d, want 400", rec.Code)
+	}
+}
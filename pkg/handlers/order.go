@@ -5,20 +5,22 @@ import (
 	"strconv"
 
 	"github.com/birddigital/store.adrienbird.net/internal/config"
+	"github.com/birddigital/store.adrienbird.net/pkg/idempotency"
 	"github.com/birddigital/store.adrienbird.net/pkg/models"
 	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
 
 type OrderHandler struct {
 	cfg    *config.Config
-	client *squarespace.Client
+	client squarespace.API
 }
 
-func NewOrderHandler(cfg *config.Config) *OrderHandler {
+func NewOrderHandler(cfg *config.Config, client squarespace.API) *OrderHandler {
 	return &OrderHandler{
 		cfg:    cfg,
-		client: squarespace.NewClient(&cfg.Squarespace),
+		client: client,
 	}
 }
 
@@ -28,6 +30,9 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 	offsetStr := c.DefaultQuery("offset", "0")
 	status := c.Query("status")
 	customerID := c.Query("customerId")
+	cursor := c.Query("cursor")
+	sort := c.Query("sort")
+	fulfillmentStatus := c.Query("fulfillmentStatus")
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil {
@@ -51,6 +56,16 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 		return
 	}
 
+	modifiedAfter, ok := parseTimeParam(c, "modifiedAfter")
+	if !ok {
+		return
+	}
+
+	modifiedBefore, ok := parseTimeParam(c, "modifiedBefore")
+	if !ok {
+		return
+	}
+
 	// Build options
 	options := []squarespace.OrderOption{
 		squarespace.WithOrderLimit(limit),
@@ -62,16 +77,26 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 	if customerID != "" {
 		options = append(options, squarespace.WithOrderCustomerID(customerID))
 	}
+	if sort != "" {
+		options = append(options, squarespace.WithOrderSort(sort))
+	}
+	if !modifiedAfter.IsZero() {
+		options = append(options, squarespace.WithOrderModifiedAfter(modifiedAfter))
+	}
+	if !modifiedBefore.IsZero() {
+		options = append(options, squarespace.WithOrderModifiedBefore(modifiedBefore))
+	}
+	if fulfillmentStatus != "" {
+		options = append(options, squarespace.WithOrderFulfillmentStatus(fulfillmentStatus))
+	}
+	if cursor != "" {
+		options = append(options, squarespace.WithOrderCursor(cursor))
+	}
 
 	// Fetch orders from Squarespace
-	orders, pagination, err := h.client.GetOrders(options...)
+	orders, pagination, err := h.client.GetOrders(c.Request.Context(), options...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Error: &models.APIError{
-				Type:    "api_error",
-				Message: "Failed to fetch orders: " + err.Error(),
-			},
-		})
+		respondError(c, err, "Failed to fetch orders")
 		return
 	}
 
@@ -97,14 +122,9 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 	}
 
 	// Fetch order from Squarespace
-	order, err := h.client.GetOrder(orderID)
+	order, err := h.client.GetOrder(c.Request.Context(), orderID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
-			Error: &models.APIError{
-				Type:    "not_found",
-				Message: "Order not found: " + err.Error(),
-			},
-		})
+		respondError(c, err, "Failed to fetch order")
 		return
 	}
 
@@ -127,36 +147,28 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	// Validate required fields
-	if order.Email == "" {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Error: &models.APIError{
-				Type:    "validation_error",
-				Message: "Email is required",
-			},
-		})
+	// Validate required fields, addresses, line items and totals
+	if errs := validation.ValidateOrder(&order); len(errs) > 0 {
+		respondValidationError(c, errs)
 		return
 	}
 
-	if len(order.LineItems) == 0 {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Error: &models.APIError{
-				Type:    "validation_error",
-				Message: "At least one line item is required",
-			},
-		})
+	// Check line item customizations against each product's custom form
+	errs, err := validation.ValidateOrderCustomizations(c.Request.Context(), order.LineItems, h.client.GetProduct)
+	if err != nil {
+		respondError(c, err, "Failed to validate order customizations")
+		return
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
 		return
 	}
 
-	// Create order in Squarespace
-	createdOrder, err := h.client.CreateOrder(&order)
+	// Create order in Squarespace, forwarding the client's idempotency key
+	ctx := squarespace.WithIdempotencyKey(c.Request.Context(), idempotency.KeyFromContext(c.Request.Context()))
+	createdOrder, err := h.client.CreateOrder(ctx, &order)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Error: &models.APIError{
-				Type:    "creation_error",
-				Message: "Failed to create order: " + err.Error(),
-			},
-		})
+		respondError(c, err, "Failed to create order")
 		return
 	}
 
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// parseTimeParam reads an optional RFC 3339 query parameter. It writes a 400
+// response and returns false when the value is malformed.
+func parseTimeParam(c *gin.Context, key string) (time.Time, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_parameter",
+				Message: "Invalid " + key + " parameter, expected RFC 3339 timestamp",
+			},
+		})
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func respondInvalidParameter(c *gin.Context, key string) {
+	c.JSON(http.StatusBadRequest, models.APIResponse{
+		Error: &models.APIError{
+			Type:    "invalid_parameter",
+			Message: "Invalid " + key + " parameter",
+		},
+	})
+}
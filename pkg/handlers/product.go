@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"github.com/birddigital/store.adrienbird.net/internal/config"
+	"github.com/birddigital/store.adrienbird.net/pkg/cache"
+	"github.com/birddigital/store.adrienbird.net/pkg/catalog"
 	"github.com/birddigital/store.adrienbird.net/pkg/models"
 	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
 	"github.com/gin-gonic/gin"
@@ -12,13 +14,13 @@ import (
 
 type ProductHandler struct {
 	cfg    *config.Config
-	client *squarespace.Client
+	client squarespace.API
 }
 
-func NewProductHandler(cfg *config.Config) *ProductHandler {
+func NewProductHandler(cfg *config.Config, client squarespace.API) *ProductHandler {
 	return &ProductHandler{
 		cfg:    cfg,
-		client: squarespace.NewClient(&cfg.Squarespace),
+		client: client,
 	}
 }
 
@@ -28,9 +30,11 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	offsetStr := c.DefaultQuery("offset", "0")
 	category := c.Query("category")
 	tag := c.Query("tag")
+	cursor := c.Query("cursor")
+	sort := c.Query("sort")
 
 	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
+	if err != nil || limit < 0 {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Error: &models.APIError{
 				Type:    "invalid_parameter",
@@ -41,7 +45,7 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
+	if err != nil || offset < 0 {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Error: &models.APIError{
 				Type:    "invalid_parameter",
@@ -51,27 +55,60 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 		return
 	}
 
-	// Build options
-	options := []squarespace.ProductOption{
-		squarespace.WithProductLimit(limit),
-		squarespace.WithProductOffset(offset),
+	modifiedAfter, ok := parseTimeParam(c, "modifiedAfter")
+	if !ok {
+		return
 	}
+
+	modifiedBefore, ok := parseTimeParam(c, "modifiedBefore")
+	if !ok {
+		return
+	}
+
+	filter, ok := parseProductFilter(c)
+	if !ok {
+		return
+	}
+	withFacets := c.Query("facets") == "true"
+
+	// Build options
+	var options []squarespace.ProductOption
 	if category != "" {
 		options = append(options, squarespace.WithProductCategory(category))
 	}
 	if tag != "" {
 		options = append(options, squarespace.WithProductTag(tag))
 	}
+	if sort != "" && !catalog.IsFilterSort(sort) {
+		options = append(options, squarespace.WithProductSort(sort))
+	}
+	if !modifiedAfter.IsZero() {
+		options = append(options, squarespace.WithProductModifiedAfter(modifiedAfter))
+	}
+	if !modifiedBefore.IsZero() {
+		options = append(options, squarespace.WithProductModifiedBefore(modifiedBefore))
+	}
+
+	// Price, stock and attribute filters are evaluated here over the whole catalog
+	if filter.Active() || withFacets {
+		h.browseProducts(c, options, filter, limit, offset)
+		return
+	}
+
+	options = append(options,
+		squarespace.WithProductLimit(limit),
+		squarespace.WithProductOffset(offset),
+	)
+	if cursor != "" {
+		options = append(options, squarespace.WithProductCursor(cursor))
+	}
 
 	// Fetch products from Squarespace
-	products, pagination, err := h.client.GetProducts(options...)
+	ctx, cacheStatus := cache.Track(c.Request.Context())
+	products, pagination, err := h.client.GetProducts(ctx, options...)
+	setCacheHeader(c, *cacheStatus)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Error: &models.APIError{
-				Type:    "api_error",
-				Message: "Failed to fetch products: " + err.Error(),
-			},
-		})
+		respondError(c, err, "Failed to fetch products")
 		return
 	}
 
@@ -84,6 +121,91 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// browseProducts walks every product matching options, applies filter and
+// answers with the requested page plus facet counts over all matches.
+func (h *ProductHandler) browseProducts(c *gin.Context, options []squarespace.ProductOption, filter catalog.Filter, limit, offset int) {
+	var products []models.Product
+	ctx, cacheStatus := cache.Track(c.Request.Context())
+	it := squarespace.NewProductIterator(ctx, h.client, options...)
+	for it.Next() {
+		products = append(products, it.Value())
+	}
+	setCacheHeader(c, *cacheStatus)
+	if err := it.Err(); err != nil {
+		respondError(c, err, "Failed to fetch products")
+		return
+	}
+
+	matched := filter.Apply(products)
+	total := len(matched)
+	page := []models.Product{}
+	if offset < total {
+		end := total
+		// Compare against the remainder so a huge limit cannot overflow
+		if limit > 0 && limit < end-offset {
+			end = offset + limit
+		}
+		page = matched[offset:end]
+	}
+
+	response := models.APIResponse{
+		Data:       page,
+		Pagination: &models.Pagination{TotalResults: &total},
+		Facets:     catalog.Aggregate(matched),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseProductFilter reads the server-side browse filters. It writes a 400
+// response and returns false when a value is malformed.
+func parseProductFilter(c *gin.Context) (catalog.Filter, bool) {
+	filter := catalog.Filter{
+		Attributes: c.QueryMap("attr"),
+		Sort:       c.Query("sort"),
+	}
+
+	for _, param := range []struct {
+		key    string
+		target **float64
+	}{
+		{"minPrice", &filter.MinPrice},
+		{"maxPrice", &filter.MaxPrice},
+	} {
+		value := c.Query(param.key)
+		if value == "" {
+			continue
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			respondInvalidParameter(c, param.key)
+			return filter, false
+		}
+		*param.target = &price
+	}
+
+	for _, param := range []struct {
+		key    string
+		target *bool
+	}{
+		{"inStock", &filter.InStock},
+		{"onSale", &filter.OnSale},
+	} {
+		value := c.Query(param.key)
+		if value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			respondInvalidParameter(c, param.key)
+			return filter, false
+		}
+		*param.target = enabled
+	}
+
+	return filter, true
+}
+
 func (h *ProductHandler) GetProduct(c *gin.Context) {
 	productID := c.Param("id")
 	if productID == "" {
@@ -97,14 +219,11 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	}
 
 	// Fetch product from Squarespace
-	product, err := h.client.GetProduct(productID)
+	ctx, cacheStatus := cache.Track(c.Request.Context())
+	product, err := h.client.GetProduct(ctx, productID)
+	setCacheHeader(c, *cacheStatus)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
-			Error: &models.APIError{
-				Type:    "not_found",
-				Message: "Product not found: " + err.Error(),
-			},
-		})
+		respondError(c, err, "Failed to fetch product")
 		return
 	}
 
@@ -128,14 +247,11 @@ func (h *ProductHandler) GetProductVariants(c *gin.Context) {
 	}
 
 	// Fetch product variants from Squarespace
-	variants, err := h.client.GetProductVariants(productID)
+	ctx, cacheStatus := cache.Track(c.Request.Context())
+	variants, err := h.client.GetProductVariants(ctx, productID)
+	setCacheHeader(c, *cacheStatus)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
-			Error: &models.APIError{
-				Type:    "not_found",
-				Message: "Product variants not found: " + err.Error(),
-			},
-		})
+		respondError(c, err, "Failed to fetch product variants")
 		return
 	}
 
@@ -144,4 +260,11 @@ func (h *ProductHandler) GetProductVariants(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+// setCacheHeader reports whether the catalog cache served the response.
+func setCacheHeader(c *gin.Context, status cache.Status) {
+	if status != "" {
+		c.Header("X-Cache", string(status))
+	}
+}
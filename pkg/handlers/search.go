@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/search"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	index *search.Index
+}
+
+func NewSearchHandler(index *search.Index) *SearchHandler {
+	return &SearchHandler{index: index}
+}
+
+func (h *SearchHandler) SearchProducts(c *gin.Context) {
+	// Parse query parameters
+	query := c.Query("q")
+	limitStr := c.DefaultQuery("limit", "20")
+	offsetStr := c.DefaultQuery("offset", "0")
+
+	if query == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "missing_parameter",
+				Message: "Search query q is required",
+			},
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_parameter",
+				Message: "Invalid limit parameter",
+			},
+		})
+		return
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_parameter",
+				Message: "Invalid offset parameter",
+			},
+		})
+		return
+	}
+
+	if h.index.Built().IsZero() {
+		c.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "index_unavailable",
+				Message: "Search index is still being built, please retry shortly",
+			},
+		})
+		return
+	}
+
+	results := h.index.Search(query, limit, offset)
+
+	response := models.APIResponse{
+		Data: results,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultTransactionWindow is how far back listings go without modifiedAfter.
+	defaultTransactionWindow = 30 * 24 * time.Hour
+	// maxOrderLookups bounds concurrent order fetches when joining a page.
+	maxOrderLookups = 8
+)
+
+// TransactionWithOrder pairs a transaction with its sales order. Order is
+// omitted when the transaction has none or it no longer exists.
+type TransactionWithOrder struct {
+	Transaction models.Transaction `json:"transaction"`
+	Order       *models.Order      `json:"order,omitempty"`
+}
+
+type TransactionHandler struct {
+	client squarespace.API
+}
+
+func NewTransactionHandler(client squarespace.API) *TransactionHandler {
+	return &TransactionHandler{client: client}
+}
+
+func (h *TransactionHandler) GetTransactions(c *gin.Context) {
+	cursor := c.Query("cursor")
+
+	modifiedAfter, ok := parseTimeParam(c, "modifiedAfter")
+	if !ok {
+		return
+	}
+
+	modifiedBefore, ok := parseTimeParam(c, "modifiedBefore")
+	if !ok {
+		return
+	}
+
+	// Squarespace needs both bounds of the window, so fill in what's missing
+	var options []squarespace.TransactionOption
+	if cursor != "" {
+		options = append(options, squarespace.WithTransactionCursor(cursor))
+	} else {
+		if modifiedBefore.IsZero() {
+			modifiedBefore = time.Now()
+		}
+		if modifiedAfter.IsZero() {
+			modifiedAfter = modifiedBefore.Add(-defaultTransactionWindow)
+		}
+		if !modifiedAfter.Before(modifiedBefore) {
+			respondInvalidParameter(c, "modifiedAfter")
+			return
+		}
+		options = append(options, squarespace.WithTransactionWindow(modifiedAfter, modifiedBefore))
+	}
+
+	transactions, pagination, err := h.client.GetTransactions(c.Request.Context(), options...)
+	if err != nil {
+		respondError(c, err, "Failed to fetch transactions")
+		return
+	}
+
+	orders, err := h.lookupOrders(c.Request.Context(), transactions)
+	if err != nil {
+		respondError(c, err, "Failed to fetch orders for transactions")
+		return
+	}
+
+	joined := make([]TransactionWithOrder, len(transactions))
+	for i, transaction := range transactions {
+		joined[i] = TransactionWithOrder{
+			Transaction: transaction,
+			Order:       orders[transaction.SalesOrderID],
+		}
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Data:       joined,
+		Pagination: pagination,
+	})
+}
+
+// lookupOrders fetches the sales order of every transaction, keyed by order
+// ID. Orders Squarespace no longer has are left out.
+func (h *TransactionHandler) lookupOrders(ctx context.Context, transactions []models.Transaction) (map[string]*models.Order, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		orders   = make(map[string]*models.Order)
+		firstErr error
+	)
+	sem := make(chan struct{}, maxOrderLookups)
+	for _, transaction := range transactions {
+		orderID := transaction.SalesOrderID
+		mu.Lock()
+		_, seen := orders[orderID]
+		if orderID == "" || seen {
+			mu.Unlock()
+			continue
+		}
+		orders[orderID] = nil
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			order, err := h.client.GetOrder(ctx, orderID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				orders[orderID] = order
+			case errors.Is(err, squarespace.ErrNotFound):
+			case firstErr == nil:
+				firstErr = err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return orders, nil
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+	"github.com/gin-gonic/gin"
+)
+
+const january = "/transactions?modifiedAfter=2024-01-01T00:00:00Z&modifiedBefore=2024-02-01T00:00:00Z"
+
+// newTransactionRouter serves the transaction listing from a seeded fake
+// holding txn-1000 for order-1000 plus transactions whose order is shared,
+// deleted or absent. Every upstream request goes through transport.
+func newTransactionRouter(t *testing.T, transport roundTripFunc) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := sstest.NewServer()
+	t.Cleanup(server.Close)
+	server.SeedDemoData()
+	modified := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	for _, transaction := range []models.Transaction{
+		{ID: "txn-1001", SalesOrderID: "order-1000", ModifiedOn: modified},
+		{ID: "txn-1002", SalesOrderID: "order-gone", ModifiedOn: modified},
+		{ID: "txn-1003", ModifiedOn: modified},
+		{ID: "txn-1004", SalesOrderID: "order-1000", ModifiedOn: modified.AddDate(0, 1, 0)},
+	} {
+		server.AddTransaction(transaction)
+	}
+
+	client := squarespace.NewClient(server.Config(), squarespace.WithTransport(transport))
+	router := gin.New()
+	router.GET("/transactions", NewTransactionHandler(client).GetTransactions)
+	return router
+}
+
+func TestGetTransactionsJoinsOrders(t *testing.T) {
+	var mu sync.Mutex
+	lookups := make(map[string]int)
+	router := newTransactionRouter(t, func(req *http.Request) (*http.Response, error) {
+		if orderID, ok := strings.CutPrefix(req.URL.Path, "/1.0/commerce/orders/"); ok {
+			mu.Lock()
+			lookups[orderID]++
+			mu.Unlock()
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	rec, response := serve(t, router, http.MethodGet, january, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var joined []TransactionWithOrder
+	if err := json.Unmarshal(response.Data, &joined); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ transaction, order string }{
+		{"txn-1000", "order-1000"},
+		{"txn-1001", "order-1000"},
+		{"txn-1002", ""},
+		{"txn-1003", ""},
+	}
+	if len(joined) != len(want) {
+		t.Fatalf("got %d transactions, want %d: %s", len(joined), len(want), rec.Body)
+	}
+	for i, w := range want {
+		orderID := ""
+		if joined[i].Order != nil {
+			orderID = joined[i].Order.ID
+		}
+		if joined[i].Transaction.ID != w.transaction || orderID != w.order {
+			t.Errorf("row %d = %s with order %q, want %s with order %q", i, joined[i].Transaction.ID, orderID, w.transaction, w.order)
+		}
+	}
+	if lookups["order-1000"] != 1 || lookups["order-gone"] != 1 || len(lookups) != 2 {
+		t.Errorf("order lookups = %v, want each order fetched once", lookups)
+	}
+	if response.Pagination == nil || response.Pagination.NextPage != nil {
+		t.Errorf("pagination = %+v, want no next page", response.Pagination)
+	}
+}
+
+func TestGetTransactionsFailsWhenAnOrderLookupFails(t *testing.T) {
+	router := newTransactionRouter(t, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/1.0/commerce/orders/order-1000" {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"type":"INVALID_REQUEST_ERROR","message":"bad order"}`)),
+				Request:    req,
+			}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	rec, response := serve(t, router, http.MethodGet, january, nil)
+	if rec.Code < 400 || response.Error == nil || response.Error.Message != "Failed to fetch orders for transactions" {
+		t.Errorf("status = %d error = %+v, want the order lookup failure", rec.Code, response.Error)
+	}
+}
+
+func TestGetTransactionsValidatesWindow(t *testing.T) {
+	router := newTransactionRouter(t, http.DefaultTransport.RoundTrip)
+
+	for _, path := range []string{
+		"/transactions?modifiedAfter=yesterday",
+		"/transactions?modifiedBefore=2024-01-01",
+		"/transactions?modifiedAfter=2024-02-01T00:00:00Z&modifiedBefore=2024-01-01T00:00:00Z",
+		"/transactions?modifiedAfter=2024-01-01T00:00:00Z&modifiedBefore=2024-01-01T00:00:00Z",
+	} {
+		rec, response := serve(t, router, http.MethodGet, path, nil)
+		if rec.Code != http.StatusBadRequest || response.Error == nil || response.Error.Type != "invalid_parameter" {
+			t.Errorf("GET %s = %d %+v, want 400 invalid_parameter", path, rec.Code, response.Error)
+		}
+	}
+
+	// Only before given: the window reaches back the default 30 days
+	rec, response := serve(t, router, http.MethodGet, "/transactions?modifiedBefore=2024-02-10T00:00:00Z", nil)
+	var joined []TransactionWithOrder
+	json.Unmarshal(response.Data, &joined)
+	if rec.Code != http.StatusOK || len(joined) != 4 {
+		t.Errorf("GET with only modifiedBefore = %d with %d transactions, want 200 with 4", rec.Code, len(joined))
+	}
+}
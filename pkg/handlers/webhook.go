@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody bounds the size of a notification we're willing to read.
+const maxWebhookBody = 1 << 20
+
+type WebhookHandler struct {
+	receiver *webhook.Receiver
+}
+
+func NewWebhookHandler(receiver *webhook.Receiver) *WebhookHandler {
+	return &WebhookHandler{receiver: receiver}
+}
+
+func (h *WebhookHandler) ReceiveSquarespace(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_request",
+				Message: "Failed to read notification body",
+			},
+		})
+		return
+	}
+
+	notification, err := h.receiver.Receive(c.Request.Context(), body, c.GetHeader(webhook.SignatureHeader))
+	switch {
+	case err == nil, errors.Is(err, webhook.ErrDuplicate):
+		// Acknowledge repeats so Squarespace stops redelivering them
+		c.Status(http.StatusOK)
+	case errors.Is(err, webhook.ErrInvalidSignature):
+		c.JSON(http.StatusUnauthorized, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_signature",
+				Message: "Notification signature does not match",
+			},
+		})
+	case errors.Is(err, webhook.ErrMalformed):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "invalid_request",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, webhook.ErrStale):
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Error: &models.APIError{
+				Type:    "stale_notification",
+				Message: "Notification is too old to be accepted",
+			},
+		})
+	default:
+		log.Printf("webhook: failed to process notification %s: %v", notification.ID, err)
+		respondError(c, err, "Failed to process notification")
+	}
+}
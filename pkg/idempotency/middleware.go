@@ -0,0 +1,171 @@
+// Package idempotency makes mutating endpoints safe to retry: a request
+// carrying an Idempotency-Key header is executed once and its response
+// replayed for later requests with the same key.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+type keyContextKey struct{}
+
+// KeyFromContext returns the key to forward upstream for the request ctx
+// belongs to, or "" when the client sent none. It is derived from the route
+// and the client's key, so reusing a key on another route can't collide
+// upstream. Attach it only to the one mutation it protects:
+//
+//	api.CreateOrder(squarespace.WithIdempotencyKey(ctx, idempotency.KeyFromContext(ctx)), order)
+func KeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(keyContextKey{}).(string)
+	return key
+}
+
+// Middleware applies idempotency to the routes it wraps. Keys are scoped per
+// route and remembered for ttl; handlers get the upstream key from
+// KeyFromContext. Requests without a key pass through untouched.
+func Middleware(store Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			abort(c, http.StatusBadRequest, "invalid_idempotency_key", "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abort(c, http.StatusBadRequest, "invalid_request", "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scopedKey := c.Request.Method + " " + c.FullPath() + " " + key
+		requestFingerprint := fingerprint(c.Request.Method, c.Request.URL.Path, body)
+		record, started, err := store.Begin(ctx, scopedKey, requestFingerprint, ttl)
+		if err != nil {
+			log.Printf("idempotency: begin %q: %v", key, err)
+			abort(c, http.StatusInternalServerError, "internal_error", "Failed to check idempotency key")
+			return
+		}
+
+		if !started {
+			switch {
+			case record.Fingerprint != requestFingerprint:
+				abort(c, http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency-Key was already used with a different request")
+			case !record.Completed:
+				abort(c, http.StatusConflict, "idempotency_in_progress", "A request with this Idempotency-Key is still being processed")
+			default:
+				replay(c, record)
+			}
+			return
+		}
+
+		// Storage outlives the request, so a client hanging up can't strand the key
+		storeCtx := context.WithoutCancel(ctx)
+		release := func() {
+			if err := store.Release(storeCtx, scopedKey); err != nil {
+				log.Printf("idempotency: release %q: %v", key, err)
+			}
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				release()
+				panic(r)
+			}
+		}()
+
+		upstreamKey := sha256.Sum256([]byte(scopedKey))
+		c.Request = c.Request.WithContext(context.WithValue(ctx, keyContextKey{}, hex.EncodeToString(upstreamKey[:])))
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Server errors and conflicts aren't remembered so the client can
+		// retry; the key forwarded upstream keeps Squarespace from
+		// duplicating work.
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusConflict || status == statusClientClosedRequest {
+			release()
+			return
+		}
+
+		completed := &Record{
+			Fingerprint: record.Fingerprint,
+			StatusCode:  status,
+			Header:      http.Header{"Content-Type": recorder.Header().Values("Content-Type")},
+			Body:        recorder.body.Bytes(),
+			ExpiresAt:   record.ExpiresAt,
+		}
+		if err := store.Complete(storeCtx, scopedKey, completed); err != nil {
+			log.Printf("idempotency: complete %q: %v", key, err)
+		}
+	}
+}
+
+// statusClientClosedRequest mirrors the status handlers use for abandoned requests.
+const statusClientClosedRequest = 499
+
+func fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(c *gin.Context, record *Record) {
+	for name, values := range record.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Header(HeaderReplayed, "true")
+	c.Status(record.StatusCode)
+	c.Writer.Write(record.Body)
+	c.Abort()
+}
+
+func abort(c *gin.Context, status int, errorType, message string) {
+	c.AbortWithStatusJSON(status, models.APIResponse{
+		Error: &models.APIError{
+			Type:    errorType,
+			Message: message,
+		},
+	})
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,145 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	idempotent := Middleware(NewMemoryStore(), time.Hour)
+	router.POST("/orders", idempotent, handler)
+	router.POST("/checkout", idempotent, handler)
+	return router
+}
+
+func post(router *gin.Engine, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareReplaysCompletedRequests(t *testing.T) {
+	calls := 0
+	router := newTestRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	first := post(router, "/orders", "key-1", `{"a":1}`)
+	second := post(router, "/orders", "key-1", `{"a":1}`)
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() || second.Header().Get(HeaderReplayed) != "true" {
+		t.Errorf("replay = %d %q (replayed %q), want the first response", second.Code, second.Body, second.Header().Get(HeaderReplayed))
+	}
+
+	if rec := post(router, "/orders", "key-1", `{"a":2}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with another body = %d, want 422", rec.Code)
+	}
+}
+
+func TestMiddlewareReleasesRetryableOutcomes(t *testing.T) {
+	tests := []struct {
+		name  string
+		first func(*gin.Context)
+	}{
+		{"server error", func(c *gin.Context) { c.Status(http.StatusBadGateway) }},
+		{"conflict", func(c *gin.Context) { c.Status(http.StatusConflict) }},
+		{"client closed", func(c *gin.Context) { c.Status(statusClientClosedRequest) }},
+		{"panic", func(c *gin.Context) { panic("handler exploded") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			router := newTestRouter(func(c *gin.Context) {
+				calls++
+				if calls == 1 {
+					tt.first(c)
+					return
+				}
+				c.Status(http.StatusCreated)
+			})
+
+			post(router, "/orders", "key-1", `{}`)
+			if rec := post(router, "/orders", "key-1", `{}`); rec.Code != http.StatusCreated || rec.Header().Get(HeaderReplayed) != "" {
+				t.Errorf("retry = %d (replayed %q), want a fresh 201", rec.Code, rec.Header().Get(HeaderReplayed))
+			}
+			if calls != 2 {
+				t.Errorf("handler ran %d times, want 2", calls)
+			}
+		})
+	}
+}
+
+func TestKeyFromContextIsScopedPerRoute(t *testing.T) {
+	keys := make(map[string]string)
+	router := newTestRouter(func(c *gin.Context) {
+		keys[c.FullPath()] = KeyFromContext(c.Request.Context())
+		c.Status(http.StatusCreated)
+	})
+
+	post(router, "/orders", "", `{}`)
+	if keys["/orders"] != "" {
+		t.Errorf("key without header = %q, want none", keys["/orders"])
+	}
+
+	post(router, "/orders", "shared", `{}`)
+	post(router, "/checkout", "shared", `{}`)
+	if keys["/orders"] == "" || keys["/checkout"] == "" {
+		t.Fatalf("keys = %v, want one per route", keys)
+	}
+	if keys["/orders"] == keys["/checkout"] || keys["/orders"] == "shared" {
+		t.Errorf("keys = %v, want distinct keys derived per route", keys)
+	}
+}
+
+func TestMiddlewareOverlappingRequestsRunOnce(t *testing.T) {
+	var calls int32
+	router := newTestRouter(func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(5 * time.Millisecond)
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	const requests = 50
+	codes := make([]int, requests)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Spread the retries across the first request's lifetime so
+			// some land while it stores its response
+			time.Sleep(time.Duration(i) * 200 * time.Microsecond)
+			codes[i] = post(router, "/orders", "key-1", `{}`).Code
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	for _, code := range codes {
+		if code != http.StatusCreated && code != http.StatusConflict {
+			t.Errorf("status = %d, want 201 or an in-progress 409", code)
+		}
+	}
+}
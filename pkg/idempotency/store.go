@@ -0,0 +1,88 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is the stored outcome of a request made with an idempotency key.
+// Until Completed, the original request is still in flight.
+type Record struct {
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Store persists idempotency records.
+type Store interface {
+	// Begin claims key for a new request, returning started=true. If the key
+	// is already claimed it returns the existing record instead. The record
+	// returned is the caller's own copy.
+	Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (record *Record, started bool, err error)
+	// Complete stores the response for a claimed key.
+	Complete(ctx context.Context, key string, record *Record) error
+	// Release drops a claimed key so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryStore keeps records in process memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+func (s *MemoryStore) Begin(_ context.Context, key, fingerprint string, ttl time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		existing := *record
+		return &existing, false, nil
+	}
+
+	record := &Record{Fingerprint: fingerprint, ExpiresAt: now.Add(ttl)}
+	s.records[key] = record
+	claimed := *record
+	return &claimed, true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	completed := *record
+	completed.Completed = true
+	s.records[key] = &completed
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// Sweep removes expired records.
+func (s *MemoryStore) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
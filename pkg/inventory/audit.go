@@ -0,0 +1,84 @@
+package inventory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Entry records one inventory adjustment: who changed which variant, how,
+// and the quantity before and after.
+type Entry struct {
+	ID        string    `json:"id"`
+	VariantID string    `json:"variantId"`
+	Actor     string    `json:"actor"`
+	Operation Operation `json:"operation"`
+	Amount    int       `json:"amount"`
+	Previous  *int      `json:"previous,omitempty"`
+	Quantity  int       `json:"quantity"`
+	Reason    string    `json:"reason,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// AuditFilter narrows List. Zero fields match everything.
+type AuditFilter struct {
+	VariantID string
+	Actor     string
+	Limit     int
+}
+
+// AuditLog stores adjustment entries.
+type AuditLog interface {
+	// Record appends entry, assigning its ID.
+	Record(ctx context.Context, entry *Entry) error
+	// List returns entries matching filter, newest first.
+	List(ctx context.Context, filter AuditFilter) ([]Entry, error)
+}
+
+// MemoryAuditLog keeps the most recent entries in process memory.
+type MemoryAuditLog struct {
+	mu       sync.Mutex
+	entries  []Entry
+	capacity int
+	nextID   int
+}
+
+// NewMemoryAuditLog keeps up to capacity entries, dropping the oldest.
+func NewMemoryAuditLog(capacity int) *MemoryAuditLog {
+	return &MemoryAuditLog{capacity: capacity}
+}
+
+func (l *MemoryAuditLog) Record(_ context.Context, entry *Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextID++
+	entry.ID = strconv.Itoa(l.nextID)
+	l.entries = append(l.entries, *entry)
+	if l.capacity > 0 && len(l.entries) > l.capacity {
+		l.entries = append([]Entry(nil), l.entries[len(l.entries)-l.capacity:]...)
+	}
+	return nil
+}
+
+func (l *MemoryAuditLog) List(_ context.Context, filter AuditFilter) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	matched := []Entry{}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		entry := l.entries[i]
+		if filter.VariantID != "" && entry.VariantID != filter.VariantID {
+			continue
+		}
+		if filter.Actor != "" && entry.Actor != filter.Actor {
+			continue
+		}
+		matched = append(matched, entry)
+		if filter.Limit > 0 && len(matched) == filter.Limit {
+			break
+		}
+	}
+	return matched, nil
+}
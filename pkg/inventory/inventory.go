@@ -0,0 +1,253 @@
+// Package inventory reads and adjusts variant stock levels in Squarespace,
+// recording every adjustment in an audit log.
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+var (
+	ErrInvalidAdjustment = errors.New("inventory: invalid adjustment")
+	ErrNotTracked        = errors.New("inventory: stock is not tracked for this variant")
+)
+
+// Operation is the kind of change an Adjustment makes.
+type Operation string
+
+const (
+	OperationIncrement Operation = "increment"
+	OperationDecrement Operation = "decrement"
+	OperationSet       Operation = "set"
+)
+
+// maxBatchConcurrency bounds upstream calls made by GetMany.
+const maxBatchConcurrency = 8
+
+// Level is the stock of one variant.
+type Level struct {
+	VariantID string              `json:"variantId"`
+	Stock     models.ProductStock `json:"stock"`
+}
+
+// Batch is the result of GetMany. Variants Squarespace doesn't know are listed
+// in NotFound rather than failing the whole batch.
+type Batch struct {
+	Items    []Level  `json:"items"`
+	NotFound []string `json:"notFound,omitempty"`
+}
+
+// Adjustment changes a variant's quantity by Quantity (increment, decrement)
+// or to Quantity (set).
+type Adjustment struct {
+	Operation Operation `json:"operation" binding:"required"`
+	Quantity  int       `json:"quantity"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// NegativeStockError is returned when a decrement would take stock below zero.
+type NegativeStockError struct {
+	VariantID string `json:"variantId"`
+	Requested int    `json:"requested"`
+	Available int    `json:"available"`
+}
+
+func (e *NegativeStockError) Error() string {
+	return fmt.Sprintf("inventory: cannot remove %d of %s, only %d in stock", e.Requested, e.VariantID, e.Available)
+}
+
+// Service reads and adjusts inventory. Adjustments of the same variant are
+// serialised so that concurrent relative changes made through this process
+// don't lose updates; different variants never wait on each other.
+type Service struct {
+	api   *squarespace.Client
+	audit AuditLog
+
+	mu    sync.Mutex
+	locks map[string]*variantLock
+}
+
+// variantLock serialises adjustments of one variant. refs counts the callers
+// holding or waiting for it, so it can be dropped once nobody needs it.
+type variantLock struct {
+	sync.Mutex
+	refs int
+}
+
+func NewService(api *squarespace.Client, audit AuditLog) *Service {
+	return &Service{api: api, audit: audit, locks: make(map[string]*variantLock)}
+}
+
+// Get returns the stock level of a variant.
+func (s *Service) Get(ctx context.Context, variantID string) (*Level, error) {
+	stock, err := s.api.GetInventory(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	return &Level{VariantID: variantID, Stock: *stock}, nil
+}
+
+// GetMany returns the stock levels of several variants, in the order given.
+// Duplicate IDs are looked up once.
+func (s *Service) GetMany(ctx context.Context, variantIDs []string) (*Batch, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var unique []string
+	seen := make(map[string]bool, len(variantIDs))
+	for _, id := range variantIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	levels := make([]*Level, len(unique))
+	errs := make([]error, len(unique))
+	sem := make(chan struct{}, maxBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, id := range unique {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			levels[i], errs[i] = s.Get(ctx, id)
+			if errs[i] != nil && !errors.Is(errs[i], squarespace.ErrNotFound) {
+				cancel()
+			}
+		}(i, id)
+	}
+	wg.Wait()
+
+	batch := &Batch{Items: []Level{}}
+	for i, id := range unique {
+		switch {
+		case errs[i] == nil:
+			batch.Items = append(batch.Items, *levels[i])
+		case errors.Is(errs[i], squarespace.ErrNotFound):
+			batch.NotFound = append(batch.NotFound, id)
+		}
+	}
+	// Report the first real failure rather than a cancellation it caused
+	var failure error
+	for _, err := range errs {
+		if err == nil || errors.Is(err, squarespace.ErrNotFound) {
+			continue
+		}
+		if failure == nil || errors.Is(failure, context.Canceled) {
+			failure = err
+		}
+	}
+	if failure != nil {
+		return nil, failure
+	}
+	return batch, nil
+}
+
+// Adjust applies adj to a variant on behalf of actor and records it in the
+// audit log. Relative adjustments need tracked, limited stock.
+func (s *Service) Adjust(ctx context.Context, variantID string, adj Adjustment, actor string) (*Entry, error) {
+	if err := adj.validate(); err != nil {
+		return nil, err
+	}
+
+	unlock := s.lock(variantID)
+	defer unlock()
+
+	current, err := s.api.GetInventory(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := adj.Quantity
+	if adj.Operation != OperationSet {
+		if current.Unlimited || !current.TrackInventory || current.Quantity == nil {
+			return nil, ErrNotTracked
+		}
+		available := *current.Quantity
+		if adj.Operation == OperationIncrement {
+			quantity = available + adj.Quantity
+		} else {
+			if adj.Quantity > available {
+				return nil, &NegativeStockError{VariantID: variantID, Requested: adj.Quantity, Available: available}
+			}
+			quantity = available - adj.Quantity
+		}
+	}
+
+	if err := s.api.UpdateInventory(ctx, variantID, quantity); err != nil {
+		return nil, err
+	}
+
+	entry := Entry{
+		VariantID: variantID,
+		Actor:     actor,
+		Operation: adj.Operation,
+		Amount:    adj.Quantity,
+		Previous:  current.Quantity,
+		Quantity:  quantity,
+		Reason:    adj.Reason,
+		At:        time.Now().UTC(),
+	}
+	// The change has already been made upstream, so a failed audit write is
+	// logged rather than reported as a failed adjustment
+	if err := s.audit.Record(ctx, &entry); err != nil {
+		log.Printf("inventory: failed to record audit entry for %s by %s: %v", variantID, actor, err)
+	}
+	log.Printf("inventory: %s %s %s by %d (now %d): %s", actor, adj.Operation, variantID, adj.Quantity, quantity, adj.Reason)
+	return &entry, nil
+}
+
+// lock takes the lock for variantID, returning the function that releases it.
+// Adjusting calls Squarespace twice, so only adjustments of the same variant
+// should wait.
+func (s *Service) lock(variantID string) (unlock func()) {
+	s.mu.Lock()
+	l, ok := s.locks[variantID]
+	if !ok {
+		l = &variantLock{}
+		s.locks[variantID] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.locks, variantID)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Audit returns recorded adjustments matching filter, newest first.
+func (s *Service) Audit(ctx context.Context, filter AuditFilter) ([]Entry, error) {
+	return s.audit.List(ctx, filter)
+}
+
+func (a Adjustment) validate() error {
+	switch a.Operation {
+	case OperationIncrement, OperationDecrement:
+		if a.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity must be positive for %s", ErrInvalidAdjustment, a.Operation)
+		}
+	case OperationSet:
+		if a.Quantity < 0 {
+			return fmt.Errorf("%w: quantity must not be negative", ErrInvalidAdjustment)
+		}
+	default:
+		return fmt.Errorf("%w: operation must be increment, decrement or set", ErrInvalidAdjustment)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+func newTestService(t *testing.T) (*sstest.Server, *squarespace.Client, *Service) {
+	t.Helper()
+	server := sstest.NewServer()
+	t.Cleanup(server.Close)
+	server.SeedDemoData()
+	client := squarespace.NewClient(server.Config())
+	return server, client, NewService(client, NewMemoryAuditLog(100))
+}
+
+func TestAdjust(t *testing.T) {
+	tests := []struct {
+		name      string
+		variantID string
+		adj       Adjustment
+		want      int
+	}{
+		{"increment", "var-print-a3", Adjustment{Operation: OperationIncrement, Quantity: 3}, 15},
+		{"decrement", "var-print-a3", Adjustment{Operation: OperationDecrement, Quantity: 12}, 0},
+		{"set", "var-print-a3", Adjustment{Operation: OperationSet, Quantity: 40}, 40},
+		{"set untracked stock", "var-ebook", Adjustment{Operation: OperationSet, Quantity: 5}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client, service := newTestService(t)
+			ctx := context.Background()
+
+			entry, err := service.Adjust(ctx, tt.variantID, tt.adj, "ada")
+			if err != nil {
+				t.Fatalf("Adjust() error = %v", err)
+			}
+			if got := stockOf(t, client, tt.variantID); got != tt.want {
+				t.Errorf("stock = %d, want %d", got, tt.want)
+			}
+			if entry.Quantity != tt.want || entry.Actor != "ada" || entry.Operation != tt.adj.Operation {
+				t.Errorf("entry = %+v, want %s by ada leaving %d", entry, tt.adj.Operation, tt.want)
+			}
+			entries, _ := service.Audit(ctx, AuditFilter{})
+			if len(entries) != 1 || entries[0].ID != entry.ID {
+				t.Errorf("audit log = %+v, want the one entry", entries)
+			}
+		})
+	}
+}
+
+func TestAdjustRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		variantID string
+		adj       Adjustment
+		wantErr   error
+	}{
+		{"increment untracked stock", "var-ebook", Adjustment{Operation: OperationIncrement, Quantity: 1}, ErrNotTracked},
+		{"zero increment", "var-print-a3", Adjustment{Operation: OperationIncrement}, ErrInvalidAdjustment},
+		{"negative set", "var-print-a3", Adjustment{Operation: OperationSet, Quantity: -1}, ErrInvalidAdjustment},
+		{"unknown operation", "var-print-a3", Adjustment{Operation: "double", Quantity: 1}, ErrInvalidAdjustment},
+		{"unknown variant", "var-missing", Adjustment{Operation: OperationSet, Quantity: 1}, squarespace.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client, service := newTestService(t)
+			ctx := context.Background()
+
+			if _, err := service.Adjust(ctx, tt.variantID, tt.adj, "ada"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Adjust() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := stockOf(t, client, "var-print-a3"); got != 12 {
+				t.Errorf("stock = %d, want it left at 12", got)
+			}
+			if entries, _ := service.Audit(ctx, AuditFilter{}); len(entries) != 0 {
+				t.Errorf("audit log = %+v, want nothing recorded", entries)
+			}
+		})
+	}
+
+	_, client, service := newTestService(t)
+	_, err := service.Adjust(context.Background(), "var-print-a2", Adjustment{Operation: OperationDecrement, Quantity: 3}, "ada")
+	var stockErr *NegativeStockError
+	if !errors.As(err, &stockErr) || stockErr.Available != 2 {
+		t.Fatalf("Adjust() below zero error = %v, want a NegativeStockError with 2 available", err)
+	}
+	if got := stockOf(t, client, "var-print-a2"); got != 2 {
+		t.Errorf("stock = %d, want it left at 2", got)
+	}
+}
+
+func TestConcurrentAdjustmentsOfOneVariantAreNotLost(t *testing.T) {
+	_, client, service := newTestService(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.Adjust(ctx, "var-print-a3", Adjustment{Operation: OperationDecrement, Quantity: 1}, "ada"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := stockOf(t, client, "var-print-a3"); got != 2 {
+		t.Errorf("stock = %d, want 2", got)
+	}
+}
+
+func TestAdjustmentsOfDifferentVariantsDoNotWait(t *testing.T) {
+	server, _, service := newTestService(t)
+	ctx := context.Background()
+
+	const latency = 100 * time.Millisecond
+	server.SetLatency(latency)
+
+	variants := []string{"var-print-a3", "var-print-a2", "var-tee-black-m", "var-tee-white-l"}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, id := range variants {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			if _, err := service.Adjust(ctx, id, Adjustment{Operation: OperationIncrement, Quantity: 1}, "ada"); err != nil {
+				t.Error(err)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	// Each adjustment makes two upstream calls; serialised they'd take
+	// variants x 2 x latency
+	if elapsed := time.Since(start); elapsed >= time.Duration(len(variants))*2*latency {
+		t.Errorf("adjusting %d variants took %v, want them adjusted concurrently", len(variants), elapsed)
+	}
+	if len(service.locks) != 0 {
+		t.Errorf("%d variant locks left behind", len(service.locks))
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	server, _, service := newTestService(t)
+	ctx := context.Background()
+
+	batch, err := service.GetMany(ctx, []string{"var-print-a2", "var-missing", "var-print-a3", "var-print-a2"})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	var ids []string
+	for _, level := range batch.Items {
+		ids = append(ids, level.VariantID)
+	}
+	if fmt.Sprint(ids) != "[var-print-a2 var-print-a3]" || fmt.Sprint(batch.NotFound) != "[var-missing]" {
+		t.Errorf("GetMany() = %v not found %v, want a2 and a3 in order with var-missing not found", ids, batch.NotFound)
+	}
+
+	server.InjectFault(sstest.Fault{Status: http.StatusBadRequest})
+	if _, err := service.GetMany(ctx, []string{"var-print-a2", "var-print-a3"}); err == nil || errors.Is(err, context.Canceled) {
+		t.Errorf("GetMany() error = %v, want the upstream failure", err)
+	}
+}
+
+func TestMemoryAuditLog(t *testing.T) {
+	ctx := context.Background()
+	log := NewMemoryAuditLog(3)
+	for i, e := range []Entry{
+		{VariantID: "a", Actor: "ada"},
+		{VariantID: "b", Actor: "ada"},
+		{VariantID: "a", Actor: "grace"},
+		{VariantID: "a", Actor: "ada"},
+	} {
+		e := e
+		if err := log.Record(ctx, &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.ID != fmt.Sprint(i+1) {
+			t.Errorf("entry %d ID = %q, want %d", i, e.ID, i+1)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   []string
+	}{
+		{"newest first, oldest dropped", AuditFilter{}, []string{"4", "3", "2"}},
+		{"by variant", AuditFilter{VariantID: "a"}, []string{"4", "3"}},
+		{"by actor", AuditFilter{Actor: "ada"}, []string{"4", "2"}},
+		{"limit", AuditFilter{Limit: 1}, []string{"4"}},
+		{"no match", AuditFilter{Actor: "nobody"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := log.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := []string{}
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("List() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
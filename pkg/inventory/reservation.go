@@ -0,0 +1,237 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/cart"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+var (
+	ErrReservationNotFound = errors.New("inventory: reservation not found or expired")
+	ErrCommitInProgress    = errors.New("inventory: reservation is already being committed")
+)
+
+// reservationActor is recorded in the audit log for stock taken by orders.
+const reservationActor = "checkout"
+
+// Hold is a quantity of one variant set aside for a reservation. Backorder
+// holds never block other shoppers; they're kept so the sale is still
+// deducted from stock on commit.
+type Hold struct {
+	VariantID string `json:"variantId"`
+	Quantity  int    `json:"quantity"`
+	Backorder bool   `json:"backorder,omitempty"`
+}
+
+// Reservation is a time-limited set of holds, keyed by the cart it was made
+// for.
+type Reservation struct {
+	Key       string    `json:"-"`
+	Holds     []Hold    `json:"holds"`
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	committing bool
+}
+
+// Reservations places holds against tracked stock while a shopper checks out.
+// Holds live in process memory; Squarespace only sees the deduction made when
+// a reservation is committed.
+type Reservations struct {
+	api       *squarespace.Client
+	inventory *Service
+	ttl       time.Duration
+
+	mu           sync.Mutex
+	reservations map[string]*Reservation
+}
+
+// NewReservations holds stock for ttl.
+func NewReservations(api *squarespace.Client, inventory *Service, ttl time.Duration) *Reservations {
+	return &Reservations{
+		api:          api,
+		inventory:    inventory,
+		ttl:          ttl,
+		reservations: make(map[string]*Reservation),
+	}
+}
+
+// Reserve holds items for key, replacing any reservation key already has.
+// Variants with unlimited or untracked stock need no hold. It returns a
+// *cart.AvailabilityError when stock not held by others can't cover items.
+func (r *Reservations) Reserve(ctx context.Context, key string, items []Hold) (*Reservation, error) {
+	var holds []Hold
+	quantities := make(map[string]int)
+	for _, item := range mergeHolds(items) {
+		stock, err := r.api.GetInventory(ctx, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case stock.Unlimited || !stock.TrackInventory:
+			continue
+		case stock.AllowBackorder:
+			item.Backorder = true
+		case stock.Quantity != nil:
+			quantities[item.VariantID] = *stock.Quantity
+		}
+		holds = append(holds, item)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	held := r.heldLocked(now, key)
+	var shortages []cart.StockError
+	for _, hold := range holds {
+		if hold.Backorder {
+			continue
+		}
+		available := quantities[hold.VariantID] - held[hold.VariantID]
+		if available < 0 {
+			available = 0
+		}
+		if hold.Quantity > available {
+			shortages = append(shortages, cart.StockError{VariantID: hold.VariantID, Requested: hold.Quantity, Available: available})
+		}
+	}
+	if len(shortages) > 0 {
+		return nil, &cart.AvailabilityError{Items: shortages}
+	}
+
+	reservation := &Reservation{Key: key, Holds: holds, ExpiresAt: now.Add(r.ttl)}
+	r.reservations[key] = reservation
+	copied := *reservation
+	return &copied, nil
+}
+
+// Get returns the active reservation for key.
+func (r *Reservations) Get(key string) (*Reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation, ok := r.reservations[key]
+	if !ok || !time.Now().Before(reservation.ExpiresAt) {
+		return nil, ErrReservationNotFound
+	}
+	copied := *reservation
+	return &copied, nil
+}
+
+// Release drops key's reservation, returning its stock to other shoppers.
+func (r *Reservations) Release(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.reservations, key)
+}
+
+// Commit deducts key's holds from Squarespace stock once its order exists.
+// reference is recorded as the audit reason. Backordered variants bottom out
+// at zero. The reservation is dropped only once every hold is deducted; holds
+// that fail stay in place, keeping their stock from being sold twice, until
+// they expire or are released.
+func (r *Reservations) Commit(ctx context.Context, key, reference string) error {
+	r.mu.Lock()
+	reservation, ok := r.reservations[key]
+	if !ok {
+		r.mu.Unlock()
+		return ErrReservationNotFound
+	}
+	if reservation.committing {
+		r.mu.Unlock()
+		return ErrCommitInProgress
+	}
+	reservation.committing = true
+	holds := append([]Hold(nil), reservation.Holds...)
+	r.mu.Unlock()
+
+	var errs []error
+	var failed []Hold
+	for _, hold := range holds {
+		_, err := r.inventory.Adjust(ctx, hold.VariantID, Adjustment{
+			Operation: OperationDecrement,
+			Quantity:  hold.Quantity,
+			Reason:    reference,
+		}, reservationActor)
+
+		var stockErr *NegativeStockError
+		switch {
+		case errors.As(err, &stockErr):
+			_, err = r.inventory.Adjust(ctx, hold.VariantID, Adjustment{
+				Operation: OperationSet,
+				Quantity:  0,
+				Reason:    reference,
+			}, reservationActor)
+		case errors.Is(err, ErrNotTracked):
+			// Tracking was switched off since the hold was placed
+			err = nil
+		}
+		if err != nil {
+			failed = append(failed, hold)
+			errs = append(errs, fmt.Errorf("deduct %d of %s: %w", hold.Quantity, hold.VariantID, err))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// A Reserve or Release for key while committing supersedes this reservation
+	if r.reservations[key] == reservation {
+		if len(failed) == 0 {
+			delete(r.reservations, key)
+		} else {
+			reservation.Holds = failed
+			reservation.committing = false
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sweep removes expired reservations.
+func (r *Reservations) Sweep() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for key, reservation := range r.reservations {
+		if !now.Before(reservation.ExpiresAt) {
+			delete(r.reservations, key)
+		}
+	}
+}
+
+// heldLocked totals unexpired holds per variant, excluding key's own.
+func (r *Reservations) heldLocked(now time.Time, key string) map[string]int {
+	held := make(map[string]int)
+	for other, reservation := range r.reservations {
+		if other == key || !now.Before(reservation.ExpiresAt) {
+			continue
+		}
+		for _, hold := range reservation.Holds {
+			if !hold.Backorder {
+				held[hold.VariantID] += hold.Quantity
+			}
+		}
+	}
+	return held
+}
+
+// mergeHolds combines holds for the same variant, keeping first-seen order.
+func mergeHolds(items []Hold) []Hold {
+	var merged []Hold
+	index := make(map[string]int, len(items))
+	for _, item := range items {
+		if i, ok := index[item.VariantID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.VariantID] = len(merged)
+		merged = append(merged, Hold{VariantID: item.VariantID, Quantity: item.Quantity})
+	}
+	return merged
+}
@@ -0,0 +1,94 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/cart"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+func newTestReservations(t *testing.T) (*sstest.Server, *squarespace.Client, *Reservations) {
+	t.Helper()
+	server := sstest.NewServer()
+	t.Cleanup(server.Close)
+	server.SeedDemoData()
+	client := squarespace.NewClient(server.Config())
+	return server, client, NewReservations(client, NewService(client, NewMemoryAuditLog(100)), time.Hour)
+}
+
+func stockOf(t *testing.T, client *squarespace.Client, variantID string) int {
+	t.Helper()
+	stock, err := client.GetInventory(context.Background(), variantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *stock.Quantity
+}
+
+func TestCommitDeductsAndDropsReservation(t *testing.T) {
+	_, client, reservations := newTestReservations(t)
+	ctx := context.Background()
+
+	if _, err := reservations.Reserve(ctx, "cart-1", []Hold{{VariantID: "var-print-a3", Quantity: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reservations.Commit(ctx, "cart-1", "order 1"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if got := stockOf(t, client, "var-print-a3"); got != 9 {
+		t.Errorf("stock = %d, want 9", got)
+	}
+	if _, err := reservations.Get("cart-1"); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("Get() after commit error = %v, want ErrReservationNotFound", err)
+	}
+	if err := reservations.Commit(ctx, "cart-1", "order 1"); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("second Commit() error = %v, want ErrReservationNotFound", err)
+	}
+}
+
+func TestFailedCommitKeepsHolds(t *testing.T) {
+	server, client, reservations := newTestReservations(t)
+	ctx := context.Background()
+
+	if _, err := reservations.Reserve(ctx, "cart-1", []Hold{{VariantID: "var-print-a2", Quantity: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.InjectFault(sstest.Fault{Status: http.StatusServiceUnavailable})
+	if err := reservations.Commit(ctx, "cart-1", "order 1"); err == nil {
+		t.Fatal("Commit() error = nil, want the upstream failure")
+	}
+	server.ClearFault()
+
+	reservation, err := reservations.Get("cart-1")
+	if err != nil {
+		t.Fatalf("Get() after failed commit error = %v, want the reservation kept", err)
+	}
+	if len(reservation.Holds) != 1 || reservation.Holds[0].Quantity != 2 {
+		t.Errorf("holds = %+v, want the undeducted hold", reservation.Holds)
+	}
+
+	// Upstream still shows the stock, but it stays held for the placed order
+	_, err = reservations.Reserve(ctx, "cart-2", []Hold{{VariantID: "var-print-a2", Quantity: 1}})
+	var availability *cart.AvailabilityError
+	if !errors.As(err, &availability) {
+		t.Errorf("Reserve() by another cart error = %v, want *cart.AvailabilityError", err)
+	}
+
+	// Retrying the commit deducts the stock and finally drops the reservation
+	if err := reservations.Commit(ctx, "cart-1", "order 1"); err != nil {
+		t.Fatalf("retried Commit() error = %v", err)
+	}
+	if got := stockOf(t, client, "var-print-a2"); got != 0 {
+		t.Errorf("stock = %d, want 0", got)
+	}
+	if _, err := reservations.Get("cart-1"); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("Get() after retried commit error = %v, want ErrReservationNotFound", err)
+	}
+}
@@ -170,10 +170,95 @@ type Address struct {
 	Phone        *string `json:"phone,omitempty"`
 }
 
+// Transaction records the money side of an order: what was charged,
+// refunded and paid in fees.
+type Transaction struct {
+	ID                string                    `json:"id"`
+	CreatedOn         time.Time                 `json:"createdOn"`
+	ModifiedOn        time.Time                 `json:"modifiedOn"`
+	CustomerEmail     string                    `json:"customerEmail,omitempty"`
+	SalesOrderID      string                    `json:"salesOrderId,omitempty"`
+	Voided            bool                      `json:"voided"`
+	TotalSales        Money                     `json:"totalSales"`
+	TotalNetSales     Money                     `json:"totalNetSales"`
+	TotalNetShipping  Money                     `json:"totalNetShipping"`
+	TotalTaxes        Money                     `json:"totalTaxes"`
+	Total             Money                     `json:"total"`
+	TotalNetPayment   Money                     `json:"totalNetPayment"`
+	Payments          []TransactionPayment      `json:"payments"`
+	SalesLineItems    []TransactionLineItem     `json:"salesLineItems"`
+	Discounts         []TransactionDiscount     `json:"discounts,omitempty"`
+	ShippingLineItems []TransactionShippingItem `json:"shippingLineItems,omitempty"`
+}
+
+type TransactionPayment struct {
+	ID                    string              `json:"id"`
+	Amount                Money               `json:"amount"`
+	RefundedAmount        Money               `json:"refundedAmount"`
+	NetAmount             Money               `json:"netAmount"`
+	CreditCardType        string              `json:"creditCardType,omitempty"`
+	Provider              string              `json:"provider"`
+	Refunds               []TransactionRefund `json:"refunds,omitempty"`
+	ProcessingFees        []ProcessingFee     `json:"processingFees,omitempty"`
+	PaidOn                time.Time           `json:"paidOn"`
+	ExternalTransactionID string              `json:"externalTransactionId,omitempty"`
+}
+
+type TransactionRefund struct {
+	ID                    string    `json:"id"`
+	Amount                Money     `json:"amount"`
+	RefundedOn            time.Time `json:"refundedOn"`
+	ExternalTransactionID string    `json:"externalTransactionId,omitempty"`
+}
+
+type ProcessingFee struct {
+	ID     string `json:"id"`
+	Amount Money  `json:"amount"`
+}
+
+type TransactionLineItem struct {
+	ID             string           `json:"id"`
+	DiscountAmount Money            `json:"discountAmount"`
+	TotalSales     Money            `json:"totalSales"`
+	TotalNetSales  Money            `json:"totalNetSales"`
+	Total          Money            `json:"total"`
+	Taxes          []TransactionTax `json:"taxes,omitempty"`
+}
+
+type TransactionTax struct {
+	Amount Money  `json:"amount"`
+	Rate   string `json:"rate"`
+	Name   string `json:"name"`
+}
+
+type TransactionDiscount struct {
+	Description string `json:"description"`
+	Name        string `json:"name"`
+	Amount      Money  `json:"amount"`
+}
+
+type TransactionShippingItem struct {
+	ID             string           `json:"id"`
+	DiscountAmount Money            `json:"discountAmount"`
+	Amount         Money            `json:"amount"`
+	NetAmount      Money            `json:"netAmount"`
+	Taxes          []TransactionTax `json:"taxes,omitempty"`
+}
+
+type WebhookSubscription struct {
+	ID          string    `json:"id"`
+	EndpointURL string    `json:"endpointUrl"`
+	Topics      []string  `json:"topics"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedOn   time.Time `json:"createdOn"`
+	UpdatedOn   time.Time `json:"updatedOn"`
+}
+
 type APIResponse struct {
 	Data       interface{} `json:"data,omitempty"`
 	Error      *APIError   `json:"error,omitempty"`
 	Pagination *Pagination `json:"pagination,omitempty"`
+	Facets     interface{} `json:"facets,omitempty"`
 }
 
 type APIError struct {
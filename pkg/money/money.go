@@ -0,0 +1,285 @@
+// Package money provides an exact monetary amount stored in minor units of
+// an ISO 4217 currency.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrInvalidCurrency  = errors.New("money: invalid currency code")
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+	ErrOverflow         = errors.New("money: amount out of range")
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// exponents lists ISO 4217 currencies whose minor unit isn't 1/100.
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Exponent returns the number of decimal places in currency's minor unit.
+func Exponent(currency string) int {
+	if exponent, ok := exponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// Money is an immutable amount. The zero value has no currency and is only
+// useful as a starting point for Add; it survives a JSON round trip.
+type Money struct {
+	minor    int64
+	currency string
+}
+
+// New returns minor units of currency, e.g. New(1250, "USD") is $12.50.
+func New(minor int64, currency string) (Money, error) {
+	if !currencyPattern.MatchString(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Parse reads a decimal string such as "12.50". Amounts with more decimal
+// places than the currency allows are rejected rather than rounded.
+func Parse(value, currency string) (Money, error) {
+	if !currencyPattern.MatchString(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+
+	exponent := Exponent(currency)
+	s := strings.TrimSpace(value)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || (hasFrac && frac == "") || len(frac) > exponent || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q for %s", ErrInvalidAmount, value, currency)
+	}
+	frac += strings.Repeat("0", exponent-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q for %s", ErrInvalidAmount, value, currency)
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// FromModel parses the Squarespace {value, currency} representation.
+func FromModel(m models.Money) (Money, error) {
+	return Parse(m.Value, m.Currency)
+}
+
+// Model converts back to the Squarespace {value, currency} representation.
+func (m Money) Model() models.Money {
+	return models.Money{Value: m.Value(), Currency: m.currency}
+}
+
+func (m Money) Minor() int64     { return m.minor }
+func (m Money) Currency() string { return m.currency }
+func (m Money) IsZero() bool     { return m.minor == 0 }
+func (m Money) IsNegative() bool { return m.minor < 0 }
+func (m Money) String() string   { return m.Value() + " " + m.currency }
+
+// Value formats the amount with the currency's number of decimal places.
+func (m Money) Value() string {
+	exponent := Exponent(m.currency)
+	minor := m.minor
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	digits := strconv.FormatInt(minor, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// compatible reports whether m and other can be combined. A zero value
+// without a currency adopts the other operand's currency.
+func (m Money) compatible(other Money) (string, error) {
+	switch {
+	case m.currency == "":
+		return other.currency, nil
+	case other.currency == "" || other.currency == m.currency:
+		return m.currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.compatible(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: m.minor + other.minor, currency: currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := m.compatible(other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{minor: m.minor - other.minor, currency: currency}, nil
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) (int, error) {
+	if _, err := m.compatible(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minor < other.minor:
+		return -1, nil
+	case m.minor > other.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Equal reports whether m and other have the same amount and currency.
+func (m Money) Equal(other Money) bool {
+	return m == other
+}
+
+// Mul multiplies by quantity, failing rather than wrapping around when the
+// result doesn't fit in int64 minor units.
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := m.minor * quantity
+	if m.minor != 0 && (product/m.minor != quantity || (m.minor == -1 && quantity == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: %s x %d", ErrOverflow, m, quantity)
+	}
+	return Money{minor: product, currency: m.currency}, nil
+}
+
+// Scale multiplies by rate, rounding half to even to the nearest minor unit,
+// e.g. for tax or percentage discounts.
+func (m Money) Scale(rate *big.Rat) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.minor), rate)
+	quotient, remainder := new(big.Int).QuoRem(product.Num(), product.Denom(), new(big.Int))
+
+	// Compare twice the remainder against the denominator to decide rounding
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	if cmp := twice.Cmp(product.Denom()); cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1) {
+		if product.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return Money{minor: quotient.Int64(), currency: m.currency}
+}
+
+// Allocate splits m by ratios without losing minor units; leftovers go one
+// unit at a time to the earliest shares.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("money: negative allocation ratio %d", ratio)
+		}
+		if int64(ratio) > math.MaxInt64-total {
+			return nil, fmt.Errorf("%w: allocation ratios sum past int64", ErrOverflow)
+		}
+		total += int64(ratio)
+	}
+	if total == 0 {
+		return nil, errors.New("money: allocation ratios sum to zero")
+	}
+
+	// Each share is at most m, but minor x ratio can overflow on the way there
+	shares := make([]Money, len(ratios))
+	remainder := m.minor
+	amount, divisor := big.NewInt(m.minor), big.NewInt(total)
+	for i, ratio := range ratios {
+		share := new(big.Int).Mul(amount, big.NewInt(int64(ratio)))
+		share.Quo(share, divisor)
+		shares[i] = Money{minor: share.Int64(), currency: m.currency}
+		remainder -= share.Int64()
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].minor += step
+		remainder -= step
+	}
+	return shares, nil
+}
+
+// Sum adds amounts, which must all share a currency.
+func Sum(amounts ...Money) (Money, error) {
+	var total Money
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Model())
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var model models.Money
+	if err := json.Unmarshal(data, &model); err != nil {
+		return err
+	}
+	// The zero Money marshals without a currency; read it back as itself
+	if model.Currency == "" && isZeroAmount(model.Value) {
+		*m = Money{}
+		return nil
+	}
+	parsed, err := FromModel(model)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// isZeroAmount reports whether value is a zero amount such as "0" or "0.00".
+func isZeroAmount(value string) bool {
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(value, "-"), ".")
+	return whole != "" && strings.Trim(whole, "0") == "" && strings.Trim(frac, "0") == ""
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,215 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func mustParse(t *testing.T, value, currency string) Money {
+	t.Helper()
+	m, err := Parse(value, currency)
+	if err != nil {
+		t.Fatalf("Parse(%q, %s) error = %v", value, currency, err)
+	}
+	return m
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		minor    int64
+		err      error
+	}{
+		{"12.50", "USD", 1250, nil},
+		{"12.5", "USD", 1250, nil},
+		{"12", "USD", 1200, nil},
+		{"-0.01", "USD", -1, nil},
+		{" 3.00 ", "EUR", 300, nil},
+		{"1500", "JPY", 1500, nil},
+		{"1.234", "KWD", 1234, nil},
+		{"12.345", "USD", 0, ErrInvalidAmount},
+		{"1.5", "JPY", 0, ErrInvalidAmount},
+		{"", "USD", 0, ErrInvalidAmount},
+		{".50", "USD", 0, ErrInvalidAmount},
+		{"12.", "USD", 0, ErrInvalidAmount},
+		{"1e3", "USD", 0, ErrInvalidAmount},
+		{"92233720368547758.08", "USD", 0, ErrInvalidAmount},
+		{"1.00", "usd", 0, ErrInvalidCurrency},
+		{"1.00", "", 0, ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.currency, func(t *testing.T) {
+			m, err := Parse(tt.value, tt.currency)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && m.Minor() != tt.minor {
+				t.Errorf("Parse() minor = %d, want %d", m.Minor(), tt.minor)
+			}
+		})
+	}
+}
+
+func TestValueFormatting(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		currency string
+		want     string
+	}{
+		{1250, "USD", "12.50"},
+		{5, "USD", "0.05"},
+		{-5, "USD", "-0.05"},
+		{0, "USD", "0.00"},
+		{1500, "JPY", "1500"},
+		{1, "KWD", "0.001"},
+	}
+	for _, tt := range tests {
+		m, _ := New(tt.minor, tt.currency)
+		if got := m.Value(); got != tt.want {
+			t.Errorf("New(%d, %s).Value() = %q, want %q", tt.minor, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestScaleRoundsHalfToEven(t *testing.T) {
+	tests := []struct {
+		amount string
+		rate   string
+		want   string
+	}{
+		{"10.00", "0.0825", "0.82"},  // 0.825 rounds to even
+		{"10.00", "0.0835", "0.84"},  // 0.835 rounds to even
+		{"10.00", "0.08251", "0.83"}, // just past half rounds up
+		{"0.05", "1/2", "0.02"},
+		{"0.07", "1/2", "0.04"},
+		{"-0.05", "1/2", "-0.02"},
+		{"-0.07", "1/2", "-0.04"},
+		{"19.99", "1/3", "6.66"},
+	}
+	for _, tt := range tests {
+		rate, ok := new(big.Rat).SetString(tt.rate)
+		if !ok {
+			t.Fatalf("bad rate %q", tt.rate)
+		}
+		if got := mustParse(t, tt.amount, "USD").Scale(rate).Value(); got != tt.want {
+			t.Errorf("%s x %s = %s, want %s", tt.amount, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name   string
+		minor  int64
+		ratios []int
+		want   []int64
+		err    bool
+	}{
+		{"even split with leftover", 100, []int{1, 1, 1}, []int64{34, 33, 33}, false},
+		{"weighted", 1000, []int{70, 20, 10}, []int64{700, 200, 100}, false},
+		{"leftover skips zero ratios", 5, []int{0, 1, 1}, []int64{0, 3, 2}, false},
+		{"negative amount", -100, []int{1, 1, 1}, []int64{-34, -33, -33}, false},
+		{"no intermediate overflow", math.MaxInt64, []int{math.MaxInt32, math.MaxInt32}, []int64{math.MaxInt64/2 + 1, math.MaxInt64 / 2}, false},
+		{"zero ratios", 100, []int{0, 0}, nil, true},
+		{"negative ratio", 100, []int{1, -1}, nil, true},
+		{"ratio sum overflows", 100, []int{math.MaxInt64, 1}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := New(tt.minor, "USD")
+			shares, err := m.Allocate(tt.ratios...)
+			if (err != nil) != tt.err {
+				t.Fatalf("Allocate() error = %v, want error %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			var sum int64
+			for i, share := range shares {
+				if share.Minor() != tt.want[i] {
+					t.Errorf("share %d = %d, want %d", i, share.Minor(), tt.want[i])
+				}
+				sum += share.Minor()
+			}
+			if sum != tt.minor {
+				t.Errorf("shares sum to %d, want %d", sum, tt.minor)
+			}
+		})
+	}
+}
+
+func TestMulOverflow(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		quantity int64
+		want     int64
+		overflow bool
+	}{
+		{1250, 3, 3750, false},
+		{-1250, 3, -3750, false},
+		{0, math.MaxInt64, 0, false},
+		{math.MaxInt64, 1, math.MaxInt64, false},
+		{math.MaxInt64/2 + 1, 2, 0, true},
+		{math.MinInt64, -1, 0, true},
+		{-1, math.MinInt64, 0, true},
+		{4611686018427387904, 4, 0, true},
+	}
+	for _, tt := range tests {
+		m, _ := New(tt.minor, "USD")
+		got, err := m.Mul(tt.quantity)
+		if tt.overflow {
+			if !errors.Is(err, ErrOverflow) {
+				t.Errorf("%d x %d error = %v, want ErrOverflow", tt.minor, tt.quantity, err)
+			}
+			continue
+		}
+		if err != nil || got.Minor() != tt.want {
+			t.Errorf("%d x %d = %d, %v; want %d", tt.minor, tt.quantity, got.Minor(), err, tt.want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		json  string
+	}{
+		{"amount", Money{minor: 1250, currency: "USD"}, `{"value":"12.50","currency":"USD"}`},
+		{"negative", Money{minor: -1, currency: "EUR"}, `{"value":"-0.01","currency":"EUR"}`},
+		{"zero decimals", Money{minor: 1500, currency: "JPY"}, `{"value":"1500","currency":"JPY"}`},
+		{"zero value", Money{}, `{"value":"0.00","currency":""}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.money)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.json {
+				t.Errorf("Marshal() = %s, want %s", data, tt.json)
+			}
+			var decoded Money
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", data, err)
+			}
+			if decoded != tt.money {
+				t.Errorf("round trip = %#v, want %#v", decoded, tt.money)
+			}
+		})
+	}
+
+	for _, bad := range []string{`{"value":"5.00","currency":""}`, `{"value":"1.005","currency":"USD"}`, `"12.50"`} {
+		var decoded Money
+		if err := json.Unmarshal([]byte(bad), &decoded); err == nil {
+			t.Errorf("Unmarshal(%s) = %v, want an error", bad, decoded)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// Package search maintains an in-process inverted index over the product
+// catalog with prefix matching, typo tolerance and facet counts.
+package search
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/catalog"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+// Field weights used for relevance ranking.
+const (
+	weightName        = 3.0
+	weightSKU         = 3.0
+	weightTag         = 2.0
+	weightCategory    = 2.0
+	weightDescription = 1.0
+)
+
+// Match quality multipliers.
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.7
+	fuzzyMatch  = 0.4
+)
+
+// Index is safe for concurrent use; Rebuild swaps in a new snapshot atomically.
+type Index struct {
+	mu       sync.RWMutex
+	products map[string]models.Product
+	postings map[string]map[string]float64 // term -> product ID -> weight
+	terms    []string                      // sorted vocabulary, for prefix lookup
+	built    time.Time
+}
+
+func NewIndex() *Index {
+	return &Index{
+		products: make(map[string]models.Product),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// Rebuild replaces the index contents with products.
+func (idx *Index) Rebuild(products []models.Product) {
+	docs := make(map[string]models.Product, len(products))
+	postings := make(map[string]map[string]float64)
+
+	add := func(productID, text string, weight float64) {
+		for _, term := range tokenize(text) {
+			if postings[term] == nil {
+				postings[term] = make(map[string]float64)
+			}
+			if weight > postings[term][productID] {
+				postings[term][productID] = weight
+			}
+		}
+	}
+
+	for _, product := range products {
+		docs[product.ID] = product
+		for _, variant := range product.Products {
+			add(product.ID, variant.Name, weightName)
+			add(product.ID, variant.SKU, weightSKU)
+			add(product.ID, variant.Description, weightDescription)
+		}
+		for _, tag := range product.Tags {
+			add(product.ID, tag, weightTag)
+		}
+		for _, category := range product.Categories {
+			add(product.ID, category, weightCategory)
+		}
+	}
+
+	terms := make([]string, 0, len(postings))
+	for term := range postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.products, idx.postings, idx.terms, idx.built = docs, postings, terms, time.Now()
+}
+
+// Refresh rebuilds the index from every product api returns.
+func (idx *Index) Refresh(ctx context.Context, api squarespace.API) error {
+	var products []models.Product
+	it := squarespace.NewProductIterator(ctx, api)
+	for it.Next() {
+		products = append(products, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	idx.Rebuild(products)
+	return nil
+}
+
+// Run refreshes the index immediately and then every interval until ctx is cancelled.
+func (idx *Index) Run(ctx context.Context, api squarespace.API, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := idx.Refresh(ctx, api); err != nil {
+			log.Printf("search: index refresh failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Built reports when the index was last rebuilt; zero if never.
+func (idx *Index) Built() time.Time {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.built
+}
+
+type Hit struct {
+	Product models.Product `json:"product"`
+	Score   float64        `json:"score"`
+}
+
+type Results struct {
+	Query  string         `json:"query"`
+	Total  int            `json:"total"`
+	Hits   []Hit          `json:"hits"`
+	Facets catalog.Facets `json:"facets"`
+}
+
+// Search returns products matching every term of query, ranked by relevance.
+// Facets are computed over all matches; limit and offset page the hits.
+func (idx *Index) Search(query string, limit, offset int) Results {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	results := Results{Query: query, Hits: []Hit{}}
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 {
+		results.Facets = facets(nil)
+		return results
+	}
+
+	var scores map[string]float64
+	for _, queryTerm := range queryTerms {
+		termScores := idx.match(queryTerm)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for productID := range scores {
+			if score, ok := termScores[productID]; ok {
+				scores[productID] += score
+			} else {
+				delete(scores, productID)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for productID, score := range scores {
+		hits = append(hits, Hit{Product: idx.products[productID], Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Product.ID < hits[j].Product.ID
+	})
+
+	results.Total = len(hits)
+	results.Facets = facets(hits)
+	if offset < len(hits) {
+		end := len(hits)
+		// Compare against the remainder so a huge limit cannot overflow
+		if limit > 0 && limit < end-offset {
+			end = offset + limit
+		}
+		results.Hits = hits[offset:end]
+	}
+	return results
+}
+
+// match scores every product containing queryTerm exactly, as a prefix or
+// within the allowed edit distance, keeping the best score per product.
+func (idx *Index) match(queryTerm string) map[string]float64 {
+	scores := make(map[string]float64)
+	apply := func(term string, quality float64) {
+		for productID, weight := range idx.postings[term] {
+			if score := weight * quality; score > scores[productID] {
+				scores[productID] = score
+			}
+		}
+	}
+
+	apply(queryTerm, exactMatch)
+
+	start := sort.SearchStrings(idx.terms, queryTerm)
+	for _, term := range idx.terms[start:] {
+		if !strings.HasPrefix(term, queryTerm) {
+			break
+		}
+		if term != queryTerm {
+			apply(term, prefixMatch)
+		}
+	}
+
+	if maxDistance := allowedTypos(queryTerm); maxDistance > 0 {
+		for _, term := range idx.terms {
+			if term == queryTerm || abs(len(term)-len(queryTerm)) > maxDistance {
+				continue
+			}
+			if levenshtein(queryTerm, term) <= maxDistance {
+				apply(term, fuzzyMatch)
+			}
+		}
+	}
+
+	return scores
+}
+
+func facets(hits []Hit) catalog.Facets {
+	products := make([]models.Product, len(hits))
+	for i, hit := range hits {
+		products[i] = hit.Product
+	}
+	return catalog.Aggregate(products)
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// allowedTypos scales typo tolerance with term length so short terms stay precise.
+func allowedTypos(term string) int {
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
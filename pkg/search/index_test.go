@@ -0,0 +1,132 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+func newTestIndex() *Index {
+	idx := NewIndex()
+	idx.Rebuild([]models.Product{
+		{
+			ID:         "tee",
+			Categories: []string{"Clothing"},
+			Tags:       []string{"apparel"},
+			Products:   []models.ProductVariant{{Name: "Organic Cotton Tee", SKU: "TEE-BLK", Description: "Soft everyday shirt"}},
+		},
+		{
+			ID:         "hoodie",
+			Categories: []string{"Clothing"},
+			Tags:       []string{"apparel", "winter"},
+			Products:   []models.ProductVariant{{Name: "Cotton Hoodie", SKU: "HOOD-GRY"}},
+		},
+		{
+			ID:         "print",
+			Categories: []string{"Art"},
+			Tags:       []string{"poster"},
+			Products:   []models.ProductVariant{{Name: "Mountain Print", SKU: "PRINT-A3"}},
+		},
+		{
+			ID:         "mug",
+			Categories: []string{"Home"},
+			Products:   []models.ProductVariant{{Name: "Printed Mug", SKU: "MUG-1", Description: "Comes in a cotton bag"}},
+		},
+	})
+	return idx
+}
+
+func TestSearch(t *testing.T) {
+	idx := newTestIndex()
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"exact name ranks above description", "cotton", []string{"hoodie", "tee", "mug"}},
+		{"case and punctuation", "TEE-blk", []string{"tee"}},
+		{"prefix", "mount", []string{"print"}},
+		{"exact ranks above prefix", "print", []string{"print", "mug"}},
+		{"one typo", "moutain", []string{"print"}},
+		{"two typos in a long term", "montaiin", []string{"print"}},
+		{"no typos in short terms", "tea", nil},
+		{"every term must match", "cotton hoodie", []string{"hoodie"}},
+		{"terms matching different products", "cotton mountain", nil},
+		{"tags and categories", "apparel clothing", []string{"hoodie", "tee"}},
+		{"no terms", " -- ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := idx.Search(tt.query, 0, 0)
+			var ids []string
+			for _, hit := range results.Hits {
+				ids = append(ids, hit.Product.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, ids, tt.want)
+			}
+			if results.Total != len(tt.want) {
+				t.Errorf("Search(%q) total = %d, want %d", tt.query, results.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSearchPagesHitsButCountsFacetsOverAll(t *testing.T) {
+	idx := newTestIndex()
+
+	tests := []struct {
+		name          string
+		limit, offset int
+		want          []string
+	}{
+		{"first page", 1, 0, []string{"hoodie"}},
+		{"second page", 1, 1, []string{"tee"}},
+		{"no limit", 0, 1, []string{"tee", "mug"}},
+		{"huge limit", math.MaxInt, 1, []string{"tee", "mug"}},
+		{"past the end", 2, 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := idx.Search("cotton", tt.limit, tt.offset)
+			var ids []string
+			for _, hit := range results.Hits {
+				ids = append(ids, hit.Product.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("Search() hits = %v, want %v", ids, tt.want)
+			}
+			if results.Total != 3 {
+				t.Errorf("Search() total = %d, want 3", results.Total)
+			}
+			if got := results.Facets.Categories; got["Clothing"] != 2 || got["Home"] != 1 || len(got) != 2 {
+				t.Errorf("category facets = %v, want Clothing:2 Home:1", got)
+			}
+			if got := results.Facets.Tags; got["apparel"] != 2 || got["winter"] != 1 || len(got) != 2 {
+				t.Errorf("tag facets = %v, want apparel:2 winter:1", got)
+			}
+		})
+	}
+}
+
+func TestAllowedTypos(t *testing.T) {
+	tests := []struct {
+		term string
+		want int
+	}{
+		{"tee", 0},
+		{"mugs", 1},
+		{"cottons", 1},
+		{"mountain", 2},
+	}
+	for _, tt := range tests {
+		if got := allowedTypos(tt.term); got != tt.want {
+			t.Errorf("allowedTypos(%q) = %d, want %d", tt.term, got, tt.want)
+		}
+	}
+}
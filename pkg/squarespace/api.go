@@ -0,0 +1,38 @@
+package squarespace
+
+import (
+	"context"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+// API is the subset of Squarespace operations handlers depend on. *Client
+// implements it; tests can substitute a fake.
+type API interface {
+	GetProducts(ctx context.Context, options ...ProductOption) ([]models.Product, *models.Pagination, error)
+	GetProduct(ctx context.Context, productID string) (*models.Product, error)
+	GetProductVariants(ctx context.Context, productID string) ([]models.ProductVariant, error)
+
+	GetOrders(ctx context.Context, options ...OrderOption) ([]models.Order, *models.Pagination, error)
+	GetOrder(ctx context.Context, orderID string) (*models.Order, error)
+	CreateOrder(ctx context.Context, order *models.Order) (*models.Order, error)
+
+	GetInventory(ctx context.Context, productID string) (*models.ProductStock, error)
+	UpdateInventory(ctx context.Context, productID string, quantity int) error
+
+	GetCustomerProfile(ctx context.Context, customerID string) (*models.Address, error)
+
+	GetTransactions(ctx context.Context, options ...TransactionOption) ([]models.Transaction, *models.Pagination, error)
+
+	CreateWebhookSubscription(ctx context.Context, endpointURL string, topics []string) (*models.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, subscriptionID, endpointURL string, topics []string) (*models.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, subscriptionID string) error
+	RotateSubscriptionSecret(ctx context.Context, subscriptionID string) (string, error)
+	SendTestNotification(ctx context.Context, subscriptionID, topic string) (int, error)
+
+	HealthCheck(ctx context.Context) error
+	BreakerState() BreakerState
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,163 @@
+package squarespace
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting Squarespace while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("squarespace: circuit breaker is open")
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Outcome is the result of a call as far as the circuit breaker is concerned.
+type Outcome int
+
+const (
+	OutcomeSuccess Outcome = iota
+	OutcomeFailure
+	// OutcomeIgnored releases the call without counting it, for calls that
+	// said nothing about upstream health, e.g. ones the caller cancelled.
+	OutcomeIgnored
+)
+
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit. Zero disables the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before allowing probes.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probes allowed while half-open; that
+	// many consecutive successes close the circuit again.
+	HalfOpenRequests int
+}
+
+// CircuitBreaker trips after repeated upstream failures so callers fail fast
+// instead of waiting on a dead upstream.
+type CircuitBreaker struct {
+	settings BreakerSettings
+
+	mu         sync.Mutex
+	state      BreakerState
+	generation uint64
+	failures   int
+	successes  int
+	inFlight   int
+	openedAt   time.Time
+}
+
+func NewCircuitBreaker(settings BreakerSettings) *CircuitBreaker {
+	if settings.HalfOpenRequests < 1 {
+		settings.HalfOpenRequests = 1
+	}
+	return &CircuitBreaker{settings: settings}
+}
+
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh(time.Now())
+	return b.state
+}
+
+// Allow reports whether a call may proceed. On success the caller must invoke
+// done exactly once with the outcome of the call.
+func (b *CircuitBreaker) Allow() (done func(outcome Outcome), err error) {
+	if b.settings.FailureThreshold <= 0 {
+		return func(Outcome) {}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh(time.Now())
+
+	switch b.state {
+	case BreakerOpen:
+		return nil, ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.inFlight >= b.settings.HalfOpenRequests {
+			return nil, ErrCircuitOpen
+		}
+		b.inFlight++
+	}
+
+	generation := b.generation
+	return func(outcome Outcome) { b.record(generation, outcome) }, nil
+}
+
+func (b *CircuitBreaker) record(generation uint64, outcome Outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Outcomes from before the last state change say nothing about the current one.
+	if generation != b.generation {
+		return
+	}
+
+	if outcome == OutcomeIgnored {
+		if b.state == BreakerHalfOpen {
+			b.inFlight--
+		}
+		return
+	}
+
+	success := outcome == OutcomeSuccess
+	switch b.state {
+	case BreakerClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.setState(BreakerOpen, time.Now())
+		}
+	case BreakerHalfOpen:
+		b.inFlight--
+		if !success {
+			b.setState(BreakerOpen, time.Now())
+			return
+		}
+		b.successes++
+		if b.successes >= b.settings.HalfOpenRequests {
+			b.setState(BreakerClosed, time.Now())
+		}
+	}
+}
+
+func (b *CircuitBreaker) refresh(now time.Time) {
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.setState(BreakerHalfOpen, now)
+	}
+}
+
+func (b *CircuitBreaker) setState(state BreakerState, now time.Time) {
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.inFlight = 0
+	if state == BreakerOpen {
+		b.openedAt = now
+	}
+}
@@ -0,0 +1,50 @@
+package squarespace
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerIgnoresCancelledCalls(t *testing.T) {
+	breaker := NewCircuitBreaker(BreakerSettings{FailureThreshold: 2, OpenTimeout: time.Millisecond})
+
+	for i := 0; i < 5; i++ {
+		done, err := breaker.Allow()
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		done(OutcomeIgnored)
+	}
+	if got := breaker.State(); got != BreakerClosed {
+		t.Fatalf("state after ignored calls = %v, want closed", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		done, _ := breaker.Allow()
+		done(OutcomeFailure)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if got := breaker.State(); got != BreakerHalfOpen {
+		t.Fatalf("state = %v, want half-open", got)
+	}
+
+	// An ignored probe hands its slot back instead of reopening the circuit.
+	done, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	done(OutcomeIgnored)
+	if got := breaker.State(); got != BreakerHalfOpen {
+		t.Fatalf("state after ignored probe = %v, want half-open", got)
+	}
+
+	done, err = breaker.Allow()
+	if errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Allow() = ErrCircuitOpen, want the probe slot released")
+	}
+	done(OutcomeSuccess)
+	if got := breaker.State(); got != BreakerClosed {
+		t.Fatalf("state after successful probe = %v, want closed", got)
+	}
+}
@@ -0,0 +1,92 @@
+package squarespace
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/cache"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+// CachedClient serves catalog reads from a cache in front of another API.
+// Everything else passes straight through.
+//
+// Cache keys carry generation numbers so that product lists, whose keys can't
+// be enumerated, are invalidated by moving to a new generation. Superseded
+// entries are left to expire.
+type CachedClient struct {
+	API
+
+	cache         *cache.Cache
+	listPolicy    cache.Policy
+	productPolicy cache.Policy
+
+	listGeneration    atomic.Uint64
+	productGeneration atomic.Uint64
+}
+
+func NewCachedClient(api API, c *cache.Cache, listPolicy, productPolicy cache.Policy) *CachedClient {
+	return &CachedClient{
+		API:           api,
+		cache:         c,
+		listPolicy:    listPolicy,
+		productPolicy: productPolicy,
+	}
+}
+
+type productPage struct {
+	Products   []models.Product   `json:"products"`
+	Pagination *models.Pagination `json:"pagination,omitempty"`
+}
+
+func productsCacheKey(generation uint64, options []ProductOption) string {
+	opts := &ProductOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return "products:" + strconv.FormatUint(generation, 10) + ":" + opts.SiteID + "?" + opts.Values().Encode()
+}
+
+func productCacheKey(generation uint64, productID string) string {
+	return "product:" + strconv.FormatUint(generation, 10) + ":" + productID
+}
+
+// InvalidateProduct drops a cached product and every cached product list,
+// since any of them may include it.
+func (c *CachedClient) InvalidateProduct(ctx context.Context, productID string) error {
+	c.listGeneration.Add(1)
+	return c.cache.Delete(ctx, productCacheKey(c.productGeneration.Load(), productID))
+}
+
+// InvalidateAll drops every cached catalog read.
+func (c *CachedClient) InvalidateAll(context.Context) error {
+	c.listGeneration.Add(1)
+	c.productGeneration.Add(1)
+	return nil
+}
+
+func (c *CachedClient) GetProducts(ctx context.Context, options ...ProductOption) ([]models.Product, *models.Pagination, error) {
+	page, err := cache.Fetch(ctx, c.cache, productsCacheKey(c.listGeneration.Load(), options), c.listPolicy, func(ctx context.Context) (productPage, error) {
+		products, pagination, err := c.API.GetProducts(ctx, options...)
+		return productPage{Products: products, Pagination: pagination}, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return page.Products, page.Pagination, nil
+}
+
+func (c *CachedClient) GetProduct(ctx context.Context, productID string) (*models.Product, error) {
+	return cache.Fetch(ctx, c.cache, productCacheKey(c.productGeneration.Load(), productID), c.productPolicy, func(ctx context.Context) (*models.Product, error) {
+		return c.API.GetProduct(ctx, productID)
+	})
+}
+
+func (c *CachedClient) GetProductVariants(ctx context.Context, productID string) ([]models.ProductVariant, error) {
+	product, err := c.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return product.Products, nil
+}
@@ -0,0 +1,186 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/internal/config"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/cassette"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+// Run with -update to re-record the cassettes under testdata against the sstest fake.
+var update = flag.Bool("update", false, "re-record cassettes in testdata")
+
+func TestReplayProducts(t *testing.T) {
+	runCassette(t, "testdata/products.json", func(t *testing.T, client *squarespace.Client) {
+		ctx := context.Background()
+
+		page, pagination, err := client.GetProducts(ctx, squarespace.WithProductLimit(2))
+		if err != nil {
+			t.Fatalf("GetProducts() error = %v", err)
+		}
+		if len(page) != 2 || pagination.NextPage == nil {
+			t.Fatalf("first page = %d products, next %v; want 2 and a cursor", len(page), pagination.NextPage)
+		}
+
+		rest, pagination, err := client.GetProducts(ctx, squarespace.WithProductLimit(2), squarespace.WithProductCursor(*pagination.NextPage))
+		if err != nil {
+			t.Fatalf("GetProducts(cursor) error = %v", err)
+		}
+		if len(rest) != 1 || pagination.NextPage != nil {
+			t.Fatalf("last page = %d products, next %v; want 1 and no cursor", len(rest), pagination.NextPage)
+		}
+
+		product, err := client.GetProduct(ctx, "prod-print")
+		if err != nil {
+			t.Fatalf("GetProduct() error = %v", err)
+		}
+		if product.Type != "PHYSICAL" || len(product.Products) == 0 || product.Products[0].SKU != "PRINT-A3" {
+			t.Errorf("GetProduct() = %+v, want the physical print with its PRINT-A3 variant", product)
+		}
+
+		if _, err := client.GetProduct(ctx, "prod-missing"); !errors.Is(err, squarespace.ErrNotFound) {
+			t.Errorf("GetProduct(missing) error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestReplayOrders(t *testing.T) {
+	runCassette(t, "testdata/orders.json", func(t *testing.T, client *squarespace.Client) {
+		ctx := context.Background()
+
+		orders, _, err := client.GetOrders(ctx, squarespace.WithOrderStatus("FULFILLED"))
+		if err != nil {
+			t.Fatalf("GetOrders() error = %v", err)
+		}
+		if len(orders) != 1 || orders[0].ID != "order-1000" {
+			t.Fatalf("GetOrders() = %v, want order-1000", orders)
+		}
+
+		order, err := client.GetOrder(ctx, "order-1000")
+		if err != nil {
+			t.Fatalf("GetOrder() error = %v", err)
+		}
+		if order.Totals.Total.Value != "40.00" {
+			t.Errorf("GetOrder() total = %s, want 40.00", order.Totals.Total.Value)
+		}
+
+		created, err := client.CreateOrder(ctx, &models.Order{
+			Email: "grace@example.com",
+			LineItems: []models.OrderLineItem{{
+				ProductID:  "prod-ebook",
+				Quantity:   1,
+				UnitPrice:  models.Money{Value: "12.00", Currency: "USD"},
+				TotalPrice: models.Money{Value: "12.00", Currency: "USD"},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("CreateOrder() error = %v", err)
+		}
+		if created.ID != "order-1001" || created.Status != "PENDING" {
+			t.Errorf("CreateOrder() = %s %s, want order-1001 PENDING", created.ID, created.Status)
+		}
+	})
+}
+
+func TestRecorderRedactsSecrets(t *testing.T) {
+	server := sstest.NewServer()
+	defer server.Close()
+	server.SeedDemoData()
+
+	// Send and receive a session cookie so both sides carry a secret besides the token
+	const cookie = "session=sstest-session-secret"
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := cassette.NewRecorder(path, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err == nil {
+			resp.Header.Set("Set-Cookie", cookie)
+		}
+		return resp, err
+	}))
+	withCookie := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req.Header.Set("Cookie", cookie)
+		return recorder.RoundTrip(req)
+	})
+
+	cfg := server.Config()
+	cfg.AccessToken = "sstest-secret-token"
+	client := squarespace.NewClient(cfg, squarespace.WithTransport(withCookie))
+	if _, err := client.GetProduct(context.Background(), "prod-print"); err != nil {
+		t.Fatalf("GetProduct() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{cfg.AccessToken, "Bearer", "sstest-session-secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+
+	recorded, err := cassette.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded.Interactions) != 1 {
+		t.Fatalf("recorded %d interactions, want 1", len(recorded.Interactions))
+	}
+	interaction := recorded.Interactions[0]
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if interaction.Request.Headers.Get(name) != "" {
+			t.Errorf("request header %s was recorded", name)
+		}
+	}
+	if interaction.Response.Headers.Get("Set-Cookie") != "" {
+		t.Error("response header Set-Cookie was recorded")
+	}
+}
+
+// runCassette runs scenario against a client replaying path, or, with
+// -update, against a seeded sstest fake while recording to path. Every
+// recorded interaction must be replayed.
+func runCassette(t *testing.T, path string, scenario func(*testing.T, *squarespace.Client)) {
+	t.Helper()
+
+	if *update {
+		server := sstest.NewServer()
+		defer server.Close()
+		server.SeedDemoData()
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatal(err)
+		}
+		recorder := cassette.NewRecorder(path, nil)
+		scenario(t, squarespace.NewClient(server.Config(), squarespace.WithTransport(recorder)))
+		return
+	}
+
+	replayer, err := cassette.LoadReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The host is never contacted; the replayer answers every request.
+	cfg := &config.SquarespaceConfig{BaseURL: "https://api.squarespace.invalid", AccessToken: "replay"}
+	scenario(t, squarespace.NewClient(cfg, squarespace.WithTransport(replayer)))
+
+	if unused := replayer.Unused(); len(unused) > 0 {
+		t.Errorf("%d recorded interactions were not replayed, first %s %s", len(unused), unused[0].Request.Method, unused[0].Request.URL)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
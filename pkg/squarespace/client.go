@@ -2,7 +2,9 @@ package squarespace
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,60 +19,184 @@ type Client struct {
 	siteID      string
 	apiKey      string
 	accessToken string
+	timeout     time.Duration
+	retry       RetryPolicy
+	breaker     *CircuitBreaker
 	httpClient  *http.Client
 }
 
-func NewClient(cfg *config.SquarespaceConfig) *Client {
-	return &Client{
+type ClientOption func(*Client)
+
+// WithHTTPClient replaces the default HTTP client, e.g. to share a tuned
+// transport across the process.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport routes requests through rt, e.g. a cassette recorder or
+// replayer. It applies to the HTTP client in effect when the option runs, so
+// pass it after WithHTTPClient.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Transport = rt
+		c.httpClient = &httpClient
+	}
+}
+
+func NewClient(cfg *config.SquarespaceConfig, options ...ClientOption) *Client {
+	c := &Client{
 		baseURL:     cfg.BaseURL,
 		siteID:      cfg.SiteID,
 		apiKey:      cfg.APIKey,
 		accessToken: cfg.AccessToken,
+		timeout:     cfg.RequestTimeout,
+		retry: RetryPolicy{
+			MaxAttempts: cfg.RetryMaxAttempts,
+			BaseDelay:   cfg.RetryBaseDelay,
+			MaxDelay:    cfg.RetryMaxDelay,
+		},
+		breaker: NewCircuitBreaker(BreakerSettings{
+			FailureThreshold: cfg.BreakerFailureThreshold,
+			OpenTimeout:      cfg.BreakerOpenTimeout,
+			HalfOpenRequests: cfg.BreakerHalfOpenRequests,
+		}),
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// BreakerState reports the state of the circuit breaker guarding the upstream.
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// withTimeout applies the client's per-call deadline to ctx, unless the caller
+// already set an earlier one.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < c.timeout {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
 }
 
-func (c *Client) makeRequest(method, endpoint string, body interface{}) (*http.Response, error) {
-	var reqBody io.Reader
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	url := c.baseURL + endpoint
-	req, err := http.NewRequest(method, url, reqBody)
+	done, err := c.breaker.Allow()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "store.adrienbird.net/1.0")
+	resp, err := c.send(ctx, method, endpoint, jsonBody)
+	done(breakerOutcome(resp, err))
+	if err != nil {
+		return nil, newTransportError(err)
+	}
+	return resp, nil
+}
+
+// breakerOutcome classifies a call for the circuit breaker. Client errors
+// count as successes; caller cancellations don't count at all.
+func breakerOutcome(resp *http.Response, err error) Outcome {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return OutcomeIgnored
+		}
+		return OutcomeFailure
+	}
+	if resp.StatusCode >= 500 {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
 
-	// Add authentication
-	if c.accessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.accessToken)
-	} else if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+// send performs the request, retrying transient failures according to the
+// client's retry policy.
+func (c *Client) send(ctx context.Context, method, endpoint string, jsonBody []byte) (*http.Response, error) {
+	idempotencyKey := idempotencyKeyFromContext(ctx)
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 || (!isIdempotentMethod(method) && idempotencyKey == "") {
+		attempts = 1
 	}
 
-	return c.httpClient.Do(req)
+	for attempt := 1; ; attempt++ {
+		var reqBody io.Reader
+		if jsonBody != nil {
+			reqBody = bytes.NewReader(jsonBody)
+		}
+
+		url := c.baseURL + endpoint
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		// Set headers
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "store.adrienbird.net/1.0")
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+
+		// Add authentication
+		if c.accessToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.accessToken)
+		} else if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if attempt >= attempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := c.retry.backoff(attempt - 1)
+		if err == nil {
+			if !isRetryableStatus(resp.StatusCode) {
+				return resp, nil
+			}
+			if after, ok := retryAfter(resp); ok {
+				// Don't stall the caller on a long upstream cool-down; surface the response instead.
+				if c.retry.MaxDelay > 0 && after > c.retry.MaxDelay {
+					return resp, nil
+				}
+				delay = after
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
 }
 
 func (c *Client) decodeResponse(resp *http.Response, target interface{}) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		var apiError models.APIError
-		if err := json.NewDecoder(resp.Body).Decode(&apiError); err != nil {
-			return fmt.Errorf("API request failed with status %d", resp.StatusCode)
-		}
-		return fmt.Errorf("API error: %s - %s", apiError.Type, apiError.Message)
+		return newResponseError(resp)
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
@@ -82,7 +208,10 @@ func (c *Client) decodeResponse(resp *http.Response, target interface{}) error {
 
 // Products API
 
-func (c *Client) GetProducts(options ...ProductOption) ([]models.Product, *models.Pagination, error) {
+func (c *Client) GetProducts(ctx context.Context, options ...ProductOption) ([]models.Product, *models.Pagination, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	opts := &ProductOptions{}
 	for _, opt := range options {
 		opt(opts)
@@ -94,36 +223,17 @@ func (c *Client) GetProducts(options ...ProductOption) ([]models.Product, *model
 	}
 
 	// Add query parameters
-	if opts.Limit > 0 || opts.Offset > 0 || opts.Category != "" || opts.Tag != "" {
-		endpoint += "?"
-		params := []string{}
-		if opts.Limit > 0 {
-			params = append(params, fmt.Sprintf("limit=%d", opts.Limit))
-		}
-		if opts.Offset > 0 {
-			params = append(params, fmt.Sprintf("offset=%d", opts.Offset))
-		}
-		if opts.Category != "" {
-			params = append(params, fmt.Sprintf("category=%s", opts.Category))
-		}
-		if opts.Tag != "" {
-			params = append(params, fmt.Sprintf("tag=%s", opts.Tag))
-		}
-		for i, param := range params {
-			if i > 0 {
-				endpoint += "&"
-			}
-			endpoint += param
-		}
+	if query := opts.Values().Encode(); query != "" {
+		endpoint += "?" + query
 	}
 
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	var response struct {
-		Result    []models.Product `json:"result"`
+		Result     []models.Product   `json:"result"`
 		Pagination *models.Pagination `json:"pagination,omitempty"`
 	}
 
@@ -134,13 +244,16 @@ func (c *Client) GetProducts(options ...ProductOption) ([]models.Product, *model
 	return response.Result, response.Pagination, nil
 }
 
-func (c *Client) GetProduct(productID string) (*models.Product, error) {
+func (c *Client) GetProduct(ctx context.Context, productID string) (*models.Product, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	endpoint := fmt.Sprintf("/1.0/commerce/products/%s", productID)
 	if c.siteID != "" {
 		endpoint = fmt.Sprintf("/1.0/commerce/sites/%s/products/%s", c.siteID, productID)
 	}
 
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -153,8 +266,8 @@ func (c *Client) GetProduct(productID string) (*models.Product, error) {
 	return &product, nil
 }
 
-func (c *Client) GetProductVariants(productID string) ([]models.ProductVariant, error) {
-	product, err := c.GetProduct(productID)
+func (c *Client) GetProductVariants(ctx context.Context, productID string) ([]models.ProductVariant, error) {
+	product, err := c.GetProduct(ctx, productID)
 	if err != nil {
 		return nil, err
 	}
@@ -163,7 +276,10 @@ func (c *Client) GetProductVariants(productID string) ([]models.ProductVariant,
 
 // Orders API
 
-func (c *Client) GetOrders(options ...OrderOption) ([]models.Order, *models.Pagination, error) {
+func (c *Client) GetOrders(ctx context.Context, options ...OrderOption) ([]models.Order, *models.Pagination, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	opts := &OrderOptions{}
 	for _, opt := range options {
 		opt(opts)
@@ -175,36 +291,17 @@ func (c *Client) GetOrders(options ...OrderOption) ([]models.Order, *models.Pagi
 	}
 
 	// Add query parameters
-	if opts.Limit > 0 || opts.Offset > 0 || opts.Status != "" || opts.CustomerID != "" {
-		endpoint += "?"
-		params := []string{}
-		if opts.Limit > 0 {
-			params = append(params, fmt.Sprintf("limit=%d", opts.Limit))
-		}
-		if opts.Offset > 0 {
-			params = append(params, fmt.Sprintf("offset=%d", opts.Offset))
-		}
-		if opts.Status != "" {
-			params = append(params, fmt.Sprintf("status=%s", opts.Status))
-		}
-		if opts.CustomerID != "" {
-			params = append(params, fmt.Sprintf("customerId=%s", opts.CustomerID))
-		}
-		for i, param := range params {
-			if i > 0 {
-				endpoint += "&"
-			}
-			endpoint += param
-		}
+	if query := opts.Values().Encode(); query != "" {
+		endpoint += "?" + query
 	}
 
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	var response struct {
-		Result    []models.Order `json:"result"`
+		Result     []models.Order     `json:"result"`
 		Pagination *models.Pagination `json:"pagination,omitempty"`
 	}
 
@@ -215,13 +312,16 @@ func (c *Client) GetOrders(options ...OrderOption) ([]models.Order, *models.Pagi
 	return response.Result, response.Pagination, nil
 }
 
-func (c *Client) GetOrder(orderID string) (*models.Order, error) {
+func (c *Client) GetOrder(ctx context.Context, orderID string) (*models.Order, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	endpoint := fmt.Sprintf("/1.0/commerce/orders/%s", orderID)
 	if c.siteID != "" {
 		endpoint = fmt.Sprintf("/1.0/commerce/sites/%s/orders/%s", c.siteID, orderID)
 	}
 
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -234,13 +334,16 @@ func (c *Client) GetOrder(orderID string) (*models.Order, error) {
 	return &order, nil
 }
 
-func (c *Client) CreateOrder(order *models.Order) (*models.Order, error) {
+func (c *Client) CreateOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	endpoint := "/1.0/commerce/orders"
 	if c.siteID != "" {
 		endpoint = fmt.Sprintf("/1.0/commerce/sites/%s/orders", c.siteID)
 	}
 
-	resp, err := c.makeRequest("POST", endpoint, order)
+	resp, err := c.makeRequest(ctx, "POST", endpoint, order)
 	if err != nil {
 		return nil, err
 	}
@@ -255,13 +358,16 @@ func (c *Client) CreateOrder(order *models.Order) (*models.Order, error) {
 
 // Inventory API
 
-func (c *Client) GetInventory(productID string) (*models.ProductStock, error) {
+func (c *Client) GetInventory(ctx context.Context, productID string) (*models.ProductStock, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	endpoint := fmt.Sprintf("/1.0/commerce/inventory/%s", productID)
 	if c.siteID != "" {
 		endpoint = fmt.Sprintf("/1.0/commerce/sites/%s/inventory/%s", c.siteID, productID)
 	}
 
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -274,7 +380,10 @@ func (c *Client) GetInventory(productID string) (*models.ProductStock, error) {
 	return &inventory, nil
 }
 
-func (c *Client) UpdateInventory(productID string, quantity int) error {
+func (c *Client) UpdateInventory(ctx context.Context, productID string, quantity int) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	endpoint := fmt.Sprintf("/1.0/commerce/inventory/%s", productID)
 	if c.siteID != "" {
 		endpoint = fmt.Sprintf("/1.0/commerce/sites/%s/inventory/%s", c.siteID, productID)
@@ -284,14 +393,14 @@ func (c *Client) UpdateInventory(productID string, quantity int) error {
 		"quantity": quantity,
 	}
 
-	resp, err := c.makeRequest("PATCH", endpoint, payload)
+	resp, err := c.makeRequest(ctx, "PATCH", endpoint, payload)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("failed to update inventory with status %d", resp.StatusCode)
+		return newResponseError(resp)
 	}
 
 	return nil
@@ -299,13 +408,16 @@ func (c *Client) UpdateInventory(productID string, quantity int) error {
 
 // Profiles API
 
-func (c *Client) GetCustomerProfile(customerID string) (*models.Address, error) {
+func (c *Client) GetCustomerProfile(ctx context.Context, customerID string) (*models.Address, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	endpoint := fmt.Sprintf("/1.0/commerce/profiles/%s", customerID)
 	if c.siteID != "" {
 		endpoint = fmt.Sprintf("/1.0/commerce/sites/%s/profiles/%s", c.siteID, customerID)
 	}
 
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -318,9 +430,203 @@ func (c *Client) GetCustomerProfile(customerID string) (*models.Address, error)
 	return &profile, nil
 }
 
+// Transactions API
+
+func (c *Client) GetTransactions(ctx context.Context, options ...TransactionOption) ([]models.Transaction, *models.Pagination, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	opts := &TransactionOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	siteID := opts.SiteID
+	if siteID == "" {
+		siteID = c.siteID
+	}
+	endpoint := "/1.0/commerce/transactions"
+	if siteID != "" {
+		endpoint = fmt.Sprintf("/1.0/commerce/sites/%s/transactions", siteID)
+	}
+
+	// Add query parameters
+	if query := opts.Values().Encode(); query != "" {
+		endpoint += "?" + query
+	}
+
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Transactions use a document envelope and cursor-only pagination
+	var response struct {
+		Documents  []models.Transaction `json:"documents"`
+		Pagination struct {
+			HasNextPage    bool   `json:"hasNextPage"`
+			NextPageCursor string `json:"nextPageCursor"`
+		} `json:"pagination"`
+	}
+
+	if err := c.decodeResponse(resp, &response); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := &models.Pagination{}
+	if response.Pagination.HasNextPage && response.Pagination.NextPageCursor != "" {
+		pagination.NextPage = &response.Pagination.NextPageCursor
+	}
+
+	return response.Documents, pagination, nil
+}
+
+// Webhook subscriptions API
+
+func (c *Client) CreateWebhookSubscription(ctx context.Context, endpointURL string, topics []string) (*models.WebhookSubscription, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	payload := map[string]interface{}{
+		"endpointUrl": endpointURL,
+		"topics":      topics,
+	}
+
+	resp, err := c.makeRequest(ctx, "POST", "/1.0/webhooks/subscriptions", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscription models.WebhookSubscription
+	if err := c.decodeResponse(resp, &subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (c *Client) ListWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.makeRequest(ctx, "GET", "/1.0/webhooks/subscriptions", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		WebhookSubscriptions []models.WebhookSubscription `json:"webhookSubscriptions"`
+	}
+
+	if err := c.decodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+
+	return response.WebhookSubscriptions, nil
+}
+
+// UpdateWebhookSubscription changes a subscription's endpoint and topics.
+// An empty endpointURL or nil topics leaves that field unchanged.
+func (c *Client) UpdateWebhookSubscription(ctx context.Context, subscriptionID, endpointURL string, topics []string) (*models.WebhookSubscription, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	payload := map[string]interface{}{}
+	if endpointURL != "" {
+		payload["endpointUrl"] = endpointURL
+	}
+	if topics != nil {
+		payload["topics"] = topics
+	}
+
+	endpoint := fmt.Sprintf("/1.0/webhooks/subscriptions/%s", subscriptionID)
+	resp, err := c.makeRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscription models.WebhookSubscription
+	if err := c.decodeResponse(resp, &subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (c *Client) DeleteWebhookSubscription(ctx context.Context, subscriptionID string) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("/1.0/webhooks/subscriptions/%s", subscriptionID)
+	resp, err := c.makeRequest(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return newResponseError(resp)
+	}
+
+	return nil
+}
+
+// RotateSubscriptionSecret replaces a subscription's signing secret and
+// returns the new one. Notifications are signed with it immediately.
+func (c *Client) RotateSubscriptionSecret(ctx context.Context, subscriptionID string) (string, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("/1.0/webhooks/subscriptions/%s/actions/rotateSecret", subscriptionID)
+	resp, err := c.makeRequest(ctx, "POST", endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Secret string `json:"secret"`
+	}
+
+	if err := c.decodeResponse(resp, &response); err != nil {
+		return "", err
+	}
+
+	return response.Secret, nil
+}
+
+// SendTestNotification asks Squarespace to deliver a sample notification for
+// topic and returns the status code our endpoint answered with.
+func (c *Client) SendTestNotification(ctx context.Context, subscriptionID, topic string) (int, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	payload := map[string]interface{}{
+		"topic": topic,
+	}
+
+	endpoint := fmt.Sprintf("/1.0/webhooks/subscriptions/%s/actions/sendTestNotification", subscriptionID)
+	resp, err := c.makeRequest(ctx, "POST", endpoint, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		StatusCode int `json:"statusCode"`
+	}
+
+	if err := c.decodeResponse(resp, &response); err != nil {
+		return 0, err
+	}
+
+	return response.StatusCode, nil
+}
+
 // Health check
 
-func (c *Client) HealthCheck() error {
+func (c *Client) HealthCheck(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	endpoint := "/1.0/commerce/products"
 	if c.siteID != "" {
 		endpoint = fmt.Sprintf("/1.0/commerce/sites/%s/products", c.siteID)
@@ -329,15 +635,15 @@ func (c *Client) HealthCheck() error {
 	// Just try to fetch one product to check API connectivity
 	endpoint += "?limit=1"
 
-	resp, err := c.makeRequest("GET", endpoint, nil)
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("API health check failed with status %d", resp.StatusCode)
+		return newResponseError(resp)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,87 @@
+package squarespace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrNotFound     = errors.New("squarespace: not found")
+	ErrUnauthorized = errors.New("squarespace: unauthorized")
+	ErrRateLimited  = errors.New("squarespace: rate limited")
+)
+
+// Error describes a failed Squarespace call. StatusCode is zero when the
+// request never got a response, in which case Err holds the transport error.
+type Error struct {
+	StatusCode int
+	Type       string
+	Message    string
+	RequestID  string
+	Retryable  bool
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("squarespace: request failed: %v", e.Err)
+	}
+	if e.Type != "" || e.Message != "" {
+		return fmt.Sprintf("squarespace: %d %s: %s", e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("squarespace: request failed with status %d", e.StatusCode)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// newResponseError builds an *Error from a failed upstream response, reading
+// the Squarespace error body when there is one.
+func newResponseError(resp *http.Response) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Retryable:  isRetryableStatus(resp.StatusCode),
+	}
+
+	var body struct {
+		Type      string `json:"type"`
+		Message   string `json:"message"`
+		ContextID string `json:"contextId"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := json.Unmarshal(data, &body); err == nil {
+		apiErr.Type = body.Type
+		apiErr.Message = body.Message
+		if body.ContextID != "" {
+			apiErr.RequestID = body.ContextID
+		}
+	}
+
+	return apiErr
+}
+
+func newTransportError(err error) *Error {
+	return &Error{
+		Type:      "network_error",
+		Message:   err.Error(),
+		Retryable: true,
+		Err:       err,
+	}
+}
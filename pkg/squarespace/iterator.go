@@ -0,0 +1,115 @@
+package squarespace
+
+import (
+	"context"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+type pageFetcher[T any] func(ctx context.Context, cursor string) ([]T, *models.Pagination, error)
+
+// Iterator walks every page of a listing, following Squarespace cursors.
+//
+//	it := client.ProductsIter(ctx)
+//	for it.Next() {
+//		product := it.Value()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator[T any] struct {
+	ctx    context.Context
+	fetch  pageFetcher[T]
+	page   []T
+	index  int
+	cursor string
+	more   bool
+	value  T
+	err    error
+}
+
+func newIterator[T any](ctx context.Context, fetch pageFetcher[T]) *Iterator[T] {
+	return &Iterator[T]{ctx: ctx, fetch: fetch, more: true}
+}
+
+// Next advances to the next item, fetching the next page when needed. It
+// returns false when the listing is exhausted or an error occurred.
+func (it *Iterator[T]) Next() bool {
+	for it.index >= len(it.page) {
+		if !it.more || it.err != nil {
+			return false
+		}
+		page, pagination, err := it.fetch(it.ctx, it.cursor)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.index = page, 0
+		it.more = pagination != nil && pagination.NextPage != nil && *pagination.NextPage != ""
+		if it.more {
+			it.cursor = *pagination.NextPage
+		}
+	}
+	it.value = it.page[it.index]
+	it.index++
+	return true
+}
+
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Cursor returns the cursor of the page after the current one, or "" on the last page.
+func (it *Iterator[T]) Cursor() string {
+	if !it.more {
+		return ""
+	}
+	return it.cursor
+}
+
+// ProductsIter iterates over every product matching options.
+func (c *Client) ProductsIter(ctx context.Context, options ...ProductOption) *Iterator[models.Product] {
+	return NewProductIterator(ctx, c, options...)
+}
+
+// OrdersIter iterates over every order matching options.
+func (c *Client) OrdersIter(ctx context.Context, options ...OrderOption) *Iterator[models.Order] {
+	return NewOrderIterator(ctx, c, options...)
+}
+
+// TransactionsIter iterates over every transaction matching options.
+func (c *Client) TransactionsIter(ctx context.Context, options ...TransactionOption) *Iterator[models.Transaction] {
+	return NewTransactionIterator(ctx, c, options...)
+}
+
+// NewProductIterator iterates over every product api returns for options.
+func NewProductIterator(ctx context.Context, api API, options ...ProductOption) *Iterator[models.Product] {
+	return newIterator(ctx, func(ctx context.Context, cursor string) ([]models.Product, *models.Pagination, error) {
+		if cursor == "" {
+			return api.GetProducts(ctx, options...)
+		}
+		return api.GetProducts(ctx, append(options[:len(options):len(options)], WithProductCursor(cursor))...)
+	})
+}
+
+// NewOrderIterator iterates over every order api returns for options.
+func NewOrderIterator(ctx context.Context, api API, options ...OrderOption) *Iterator[models.Order] {
+	return newIterator(ctx, func(ctx context.Context, cursor string) ([]models.Order, *models.Pagination, error) {
+		if cursor == "" {
+			return api.GetOrders(ctx, options...)
+		}
+		return api.GetOrders(ctx, append(options[:len(options):len(options)], WithOrderCursor(cursor))...)
+	})
+}
+
+// NewTransactionIterator iterates over every transaction api returns for options.
+func NewTransactionIterator(ctx context.Context, api API, options ...TransactionOption) *Iterator[models.Transaction] {
+	return newIterator(ctx, func(ctx context.Context, cursor string) ([]models.Transaction, *models.Pagination, error) {
+		if cursor == "" {
+			return api.GetTransactions(ctx, options...)
+		}
+		return api.GetTransactions(ctx, append(options[:len(options):len(options)], WithTransactionCursor(cursor))...)
+	})
+}
@@ -1,11 +1,22 @@
 package squarespace
 
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
 type ProductOptions struct {
 	SiteID   string
 	Limit    int
 	Offset   int
 	Category string
 	Tag      string
+	Cursor   string
+
+	Sort           string
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
 }
 
 type ProductOption func(*ProductOptions)
@@ -40,12 +51,73 @@ func WithProductTag(tag string) ProductOption {
 	}
 }
 
+// WithProductCursor resumes listing from a cursor returned in
+// Pagination.NextPage. Other filters are ignored when a cursor is set.
+func WithProductCursor(cursor string) ProductOption {
+	return func(opts *ProductOptions) {
+		opts.Cursor = cursor
+	}
+}
+
+// WithProductSort orders results by a field, prefixed with "-" for descending.
+func WithProductSort(sort string) ProductOption {
+	return func(opts *ProductOptions) {
+		opts.Sort = sort
+	}
+}
+
+func WithProductModifiedAfter(t time.Time) ProductOption {
+	return func(opts *ProductOptions) {
+		opts.ModifiedAfter = t
+	}
+}
+
+func WithProductModifiedBefore(t time.Time) ProductOption {
+	return func(opts *ProductOptions) {
+		opts.ModifiedBefore = t
+	}
+}
+
+// Values encodes the options as upstream query parameters. Squarespace
+// rejects filters alongside a cursor, so only the cursor is sent when set.
+func (opts *ProductOptions) Values() url.Values {
+	values := url.Values{}
+	if opts.Cursor != "" {
+		values.Set("cursor", opts.Cursor)
+		return values
+	}
+	if opts.Limit > 0 {
+		values.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		values.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.Category != "" {
+		values.Set("category", opts.Category)
+	}
+	if opts.Tag != "" {
+		values.Set("tag", opts.Tag)
+	}
+	if opts.Sort != "" {
+		values.Set("sort", opts.Sort)
+	}
+	setTime(values, "modifiedAfter", opts.ModifiedAfter)
+	setTime(values, "modifiedBefore", opts.ModifiedBefore)
+	return values
+}
+
 type OrderOptions struct {
 	SiteID     string
 	Limit      int
 	Offset     int
 	Status     string
 	CustomerID string
+	Cursor     string
+
+	Sort              string
+	ModifiedAfter     time.Time
+	ModifiedBefore    time.Time
+	FulfillmentStatus string
 }
 
 type OrderOption func(*OrderOptions)
@@ -78,4 +150,121 @@ func WithOrderCustomerID(customerID string) OrderOption {
 	return func(opts *OrderOptions) {
 		opts.CustomerID = customerID
 	}
-}
\ No newline at end of file
+}
+
+// WithOrderCursor resumes listing from a cursor returned in
+// Pagination.NextPage. Other filters are ignored when a cursor is set.
+func WithOrderCursor(cursor string) OrderOption {
+	return func(opts *OrderOptions) {
+		opts.Cursor = cursor
+	}
+}
+
+// WithOrderSort orders results by a field, prefixed with "-" for descending.
+func WithOrderSort(sort string) OrderOption {
+	return func(opts *OrderOptions) {
+		opts.Sort = sort
+	}
+}
+
+func WithOrderModifiedAfter(t time.Time) OrderOption {
+	return func(opts *OrderOptions) {
+		opts.ModifiedAfter = t
+	}
+}
+
+func WithOrderModifiedBefore(t time.Time) OrderOption {
+	return func(opts *OrderOptions) {
+		opts.ModifiedBefore = t
+	}
+}
+
+func WithOrderFulfillmentStatus(status string) OrderOption {
+	return func(opts *OrderOptions) {
+		opts.FulfillmentStatus = status
+	}
+}
+
+// Values encodes the options as upstream query parameters. Squarespace
+// rejects filters alongside a cursor, so only the cursor is sent when set.
+func (opts *OrderOptions) Values() url.Values {
+	values := url.Values{}
+	if opts.Cursor != "" {
+		values.Set("cursor", opts.Cursor)
+		return values
+	}
+	if opts.Limit > 0 {
+		values.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		values.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.Status != "" {
+		values.Set("status", opts.Status)
+	}
+	if opts.CustomerID != "" {
+		values.Set("customerId", opts.CustomerID)
+	}
+	if opts.Sort != "" {
+		values.Set("sort", opts.Sort)
+	}
+	if opts.FulfillmentStatus != "" {
+		values.Set("fulfillmentStatus", opts.FulfillmentStatus)
+	}
+	setTime(values, "modifiedAfter", opts.ModifiedAfter)
+	setTime(values, "modifiedBefore", opts.ModifiedBefore)
+	return values
+}
+
+type TransactionOptions struct {
+	SiteID         string
+	Cursor         string
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+}
+
+type TransactionOption func(*TransactionOptions)
+
+// WithTransactionSiteID lists another site's transactions instead of the
+// configured site's.
+func WithTransactionSiteID(siteID string) TransactionOption {
+	return func(opts *TransactionOptions) {
+		opts.SiteID = siteID
+	}
+}
+
+// WithTransactionCursor resumes listing from a cursor returned in
+// Pagination.NextPage. The window is ignored when a cursor is set.
+func WithTransactionCursor(cursor string) TransactionOption {
+	return func(opts *TransactionOptions) {
+		opts.Cursor = cursor
+	}
+}
+
+// WithTransactionWindow limits results to transactions modified between
+// after and before. Squarespace requires both bounds together.
+func WithTransactionWindow(after, before time.Time) TransactionOption {
+	return func(opts *TransactionOptions) {
+		opts.ModifiedAfter = after
+		opts.ModifiedBefore = before
+	}
+}
+
+// Values encodes the options as upstream query parameters. Squarespace
+// rejects filters alongside a cursor, so only the cursor is sent when set.
+func (opts *TransactionOptions) Values() url.Values {
+	values := url.Values{}
+	if opts.Cursor != "" {
+		values.Set("cursor", opts.Cursor)
+		return values
+	}
+	setTime(values, "modifiedAfter", opts.ModifiedAfter)
+	setTime(values, "modifiedBefore", opts.ModifiedBefore)
+	return values
+}
+
+func setTime(values url.Values, key string, t time.Time) {
+	if !t.IsZero() {
+		values.Set(key, t.UTC().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,232 @@
+package squarespace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/internal/config"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+// scriptedServer answers each request with the next status in statuses,
+// repeating the last one once the script runs out.
+type scriptedServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	requests []*http.Request
+}
+
+func newScriptedServer(t *testing.T, statuses ...int) *scriptedServer {
+	t.Helper()
+	s := &scriptedServer{statuses: statuses, header: http.Header{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *scriptedServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	status := s.statuses[0]
+	if len(s.statuses) > 1 {
+		s.statuses = s.statuses[1:]
+	}
+	s.requests = append(s.requests, r.Clone(context.Background()))
+	for name, values := range s.header {
+		w.Header()[name] = values
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if status < 400 {
+		w.Write([]byte(`{"id":"p1","name":"Print"}`))
+		return
+	}
+	w.Write([]byte(`{"type":"ERROR","message":"scripted failure"}`))
+}
+
+func (s *scriptedServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func newRetryTestClient(baseURL string, policy RetryPolicy) *Client {
+	return NewClient(&config.SquarespaceConfig{
+		BaseURL:          baseURL,
+		RequestTimeout:   5 * time.Second,
+		RetryMaxAttempts: policy.MaxAttempts,
+		RetryBaseDelay:   policy.BaseDelay,
+		RetryMaxDelay:    policy.MaxDelay,
+	})
+}
+
+func TestRetryBacksOffOnTransientStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+	}{
+		{"rate limited", []int{http.StatusTooManyRequests, http.StatusOK}},
+		{"server errors", []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}},
+		{"gateway timeout", []int{http.StatusGatewayTimeout, http.StatusOK}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newScriptedServer(t, tt.statuses...)
+			client := newRetryTestClient(server.URL, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+
+			product, err := client.GetProduct(context.Background(), "p1")
+			if err != nil {
+				t.Fatalf("GetProduct() error = %v", err)
+			}
+			if product.ID != "p1" {
+				t.Errorf("GetProduct() id = %q, want p1", product.ID)
+			}
+			if got := server.count(); got != len(tt.statuses) {
+				t.Errorf("requests = %d, want %d", got, len(tt.statuses))
+			}
+		})
+	}
+}
+
+func TestRetryDoesNotRetryClientErrors(t *testing.T) {
+	server := newScriptedServer(t, http.StatusNotFound, http.StatusOK)
+	client := newRetryTestClient(server.URL, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	_, err := client.GetProduct(context.Background(), "p1")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetProduct() error = %v, want ErrNotFound", err)
+	}
+	if got := server.count(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	server := newScriptedServer(t, http.StatusTooManyRequests, http.StatusOK)
+	server.header.Set("Retry-After", "1")
+	client := newRetryTestClient(server.URL, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second})
+
+	start := time.Now()
+	if _, err := client.GetProduct(context.Background(), "p1"); err != nil {
+		t.Fatalf("GetProduct() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+	if got := server.count(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestRetrySurfacesRetryAfterBeyondMaxDelay(t *testing.T) {
+	server := newScriptedServer(t, http.StatusTooManyRequests, http.StatusOK)
+	server.header.Set("Retry-After", "120")
+	client := newRetryTestClient(server.URL, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+
+	_, err := client.GetProduct(context.Background(), "p1")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("GetProduct() error = %v, want ErrRateLimited", err)
+	}
+	if got := server.count(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryStopsAtMaxAttempts(t *testing.T) {
+	server := newScriptedServer(t, http.StatusServiceUnavailable)
+	client := newRetryTestClient(server.URL, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	_, err := client.GetProduct(context.Background(), "p1")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetProduct() error = %v, want *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || !apiErr.Retryable {
+		t.Errorf("error = %+v, want retryable 503", apiErr)
+	}
+	if got := server.count(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestRetrySkipsPostWithoutIdempotencyKey(t *testing.T) {
+	server := newScriptedServer(t, http.StatusServiceUnavailable, http.StatusOK)
+	client := newRetryTestClient(server.URL, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	if _, err := client.CreateOrder(context.Background(), &models.Order{}); err == nil {
+		t.Fatal("CreateOrder() error = nil, want the 503")
+	}
+	if got := server.count(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryRetriesPostWithIdempotencyKey(t *testing.T) {
+	server := newScriptedServer(t, http.StatusServiceUnavailable, http.StatusOK)
+	client := newRetryTestClient(server.URL, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	ctx := WithIdempotencyKey(context.Background(), "order-key")
+	if _, err := client.CreateOrder(ctx, &models.Order{}); err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	if got := server.count(); got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+	for i, req := range server.requests {
+		if got := req.Header.Get("Idempotency-Key"); got != "order-key" {
+			t.Errorf("request %d Idempotency-Key = %q, want order-key", i, got)
+		}
+	}
+}
+
+func TestRetryStopsWhenContextCancelled(t *testing.T) {
+	server := newScriptedServer(t, http.StatusServiceUnavailable)
+	client := newRetryTestClient(server.URL, RetryPolicy{MaxAttempts: 10, BaseDelay: time.Minute, MaxDelay: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.GetProduct(ctx, "p1")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetProduct() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetProduct() returned after %v, want prompt return on cancel", elapsed)
+	}
+	if got := server.count(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestBackoffStaysWithinBounds(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	tests := []struct {
+		retry int
+		max   time.Duration
+	}{
+		{0, 10 * time.Millisecond},
+		{1, 20 * time.Millisecond},
+		{2, 40 * time.Millisecond},
+		{3, 50 * time.Millisecond},
+		{40, 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := policy.backoff(tt.retry); got < 0 || got > tt.max {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", tt.retry, got, tt.max)
+			}
+		}
+	}
+}
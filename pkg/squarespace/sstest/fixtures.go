@@ -0,0 +1,195 @@
+package sstest
+
+import (
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+// SeedDemoData loads a small catalog, customer and order, enough to click
+// through the storefront offline.
+func (s *Server) SeedDemoData() {
+	published := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+	for _, product := range DemoProducts(published) {
+		s.AddProduct(product)
+	}
+
+	customerID := "customer-1"
+	s.AddProfile(customerID, models.Address{
+		FirstName:    "Ada",
+		LastName:     "Lovelace",
+		AddressLine1: "12 Analytical Way",
+		City:         "Portland",
+		State:        stringPtr("OR"),
+		PostalCode:   "97201",
+		Country:      "US",
+	})
+
+	s.AddOrder(models.Order{
+		ID:          "order-1000",
+		OrderNumber: "1000",
+		CustomerID:  &customerID,
+		Email:       "ada@example.com",
+		BillingAddress: models.Address{
+			FirstName:    "Ada",
+			LastName:     "Lovelace",
+			AddressLine1: "12 Analytical Way",
+			City:         "Portland",
+			State:        stringPtr("OR"),
+			PostalCode:   "97201",
+			Country:      "US",
+		},
+		LineItems: []models.OrderLineItem{{
+			ProductID:   "prod-print",
+			VariantID:   "var-print-a3",
+			SKU:         "PRINT-A3",
+			ProductName: "Art Print",
+			Quantity:    1,
+			UnitPrice:   usd("35.00"),
+			TotalPrice:  usd("35.00"),
+		}},
+		Totals: models.OrderTotals{
+			Subtotal: usd("35.00"),
+			Tax:      usd("0.00"),
+			Shipping: usd("5.00"),
+			Discount: usd("0.00"),
+			Total:    usd("40.00"),
+		},
+		Status:     "FULFILLED",
+		SystemData: models.SystemData{CreatedOn: published, ModifiedOn: published},
+	})
+
+	paidOn := time.UnixMilli(published).UTC()
+	s.AddTransaction(models.Transaction{
+		ID:               "txn-1000",
+		CreatedOn:        paidOn,
+		ModifiedOn:       paidOn,
+		CustomerEmail:    "ada@example.com",
+		SalesOrderID:     "order-1000",
+		TotalSales:       usd("35.00"),
+		TotalNetSales:    usd("35.00"),
+		TotalNetShipping: usd("5.00"),
+		TotalTaxes:       usd("0.00"),
+		Total:            usd("40.00"),
+		TotalNetPayment:  usd("38.54"),
+		Payments: []models.TransactionPayment{{
+			ID:             "payment-1000",
+			Amount:         usd("40.00"),
+			RefundedAmount: usd("0.00"),
+			NetAmount:      usd("38.54"),
+			CreditCardType: "VISA",
+			Provider:       "STRIPE",
+			ProcessingFees: []models.ProcessingFee{{ID: "fee-1000", Amount: usd("1.46")}},
+			PaidOn:         paidOn,
+		}},
+		SalesLineItems: []models.TransactionLineItem{{
+			ID:             "sale-1000",
+			DiscountAmount: usd("0.00"),
+			TotalSales:     usd("35.00"),
+			TotalNetSales:  usd("35.00"),
+			Total:          usd("35.00"),
+		}},
+	})
+}
+
+// DemoProducts returns the catalog used by SeedDemoData.
+func DemoProducts(published int64) []models.Product {
+	system := models.SystemData{CreatedOn: published, ModifiedOn: published, PublishedOn: published}
+
+	return []models.Product{
+		{
+			ID:         "prod-print",
+			Type:       "PHYSICAL",
+			Categories: []string{"prints"},
+			Tags:       []string{"art", "wall decor"},
+			Products: []models.ProductVariant{
+				{
+					ID:          "var-print-a3",
+					SKU:         "PRINT-A3",
+					Name:        "Art Print",
+					Description: "Giclee print on archival matte paper",
+					Pricing:     models.ProductPricing{BasePrice: moneyPtr("35.00")},
+					Stock:       stock(12),
+					Visibility:  "VISIBLE",
+					Variants:    []models.VariantOption{{Name: "Size", Option: "A3"}},
+				},
+				{
+					ID:          "var-print-a2",
+					SKU:         "PRINT-A2",
+					Name:        "Art Print",
+					Description: "Giclee print on archival matte paper",
+					Pricing:     models.ProductPricing{BasePrice: moneyPtr("55.00"), SalePrice: moneyPtr("45.00"), OnSale: true},
+					Stock:       stock(2),
+					Visibility:  "VISIBLE",
+					Variants:    []models.VariantOption{{Name: "Size", Option: "A2"}},
+				},
+			},
+			SystemData: system,
+		},
+		{
+			ID:         "prod-tee",
+			Type:       "PHYSICAL",
+			Categories: []string{"apparel"},
+			Tags:       []string{"cotton"},
+			Products: []models.ProductVariant{
+				{
+					ID:         "var-tee-black-m",
+					SKU:        "TEE-BLK-M",
+					Name:       "Logo Tee",
+					Pricing:    models.ProductPricing{BasePrice: moneyPtr("28.00")},
+					Stock:      stock(0),
+					Visibility: "VISIBLE",
+					Attributes: []models.ProductAttribute{{Name: "Material", Value: "Organic cotton"}},
+					Variants:   []models.VariantOption{{Name: "Color", Option: "Black"}, {Name: "Size", Option: "M"}},
+				},
+				{
+					ID:         "var-tee-white-l",
+					SKU:        "TEE-WHT-L",
+					Name:       "Logo Tee",
+					Pricing:    models.ProductPricing{BasePrice: moneyPtr("28.00")},
+					Stock:      stock(7),
+					Visibility: "VISIBLE",
+					Attributes: []models.ProductAttribute{{Name: "Material", Value: "Organic cotton"}},
+					Variants:   []models.VariantOption{{Name: "Color", Option: "White"}, {Name: "Size", Option: "L"}},
+				},
+			},
+			SystemData: system,
+		},
+		{
+			ID:         "prod-ebook",
+			Type:       "DIGITAL",
+			Categories: []string{"books"},
+			Tags:       []string{"digital", "design"},
+			Products: []models.ProductVariant{
+				{
+					ID:          "var-ebook",
+					SKU:         "EBOOK-01",
+					Name:        "Design Notes eBook",
+					Description: "A collection of essays on visual design",
+					Pricing:     models.ProductPricing{BasePrice: moneyPtr("12.00")},
+					Stock:       models.ProductStock{Unlimited: true},
+					Visibility:  "VISIBLE",
+				},
+			},
+			SystemData: system,
+		},
+	}
+}
+
+func usd(value string) models.Money {
+	return models.Money{Value: value, Currency: "USD"}
+}
+
+func moneyPtr(value string) *models.Money {
+	money := usd(value)
+	return &money
+}
+
+func stock(quantity int) models.ProductStock {
+	return models.ProductStock{TrackInventory: true, Quantity: &quantity}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
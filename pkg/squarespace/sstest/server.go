@@ -0,0 +1,538 @@
+// Package sstest provides an in-process fake of the Squarespace Commerce API
+// for tests and offline development.
+package sstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/internal/config"
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+// Fault makes the server fail the next Count requests with Status. Count
+// zero fails every request until the fault is cleared.
+type Fault struct {
+	Status     int
+	Count      int
+	RetryAfter string
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	products     map[string]models.Product
+	orders       map[string]models.Order
+	inventory    map[string]models.ProductStock
+	profiles     map[string]models.Address
+	transactions map[string]models.Transaction
+	nextOrder    int
+	latency      time.Duration
+	fault        *Fault
+	requests     int
+
+	subscriptions    map[string]models.WebhookSubscription
+	nextSubscription int
+	nextNotification int
+}
+
+// NewServer starts a fake with no fixtures. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		products:     make(map[string]models.Product),
+		orders:       make(map[string]models.Order),
+		inventory:    make(map[string]models.ProductStock),
+		profiles:     make(map[string]models.Address),
+		transactions: make(map[string]models.Transaction),
+		nextOrder:    1000,
+
+		subscriptions: make(map[string]models.WebhookSubscription),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Config returns a Squarespace config pointing at the fake.
+func (s *Server) Config() *config.SquarespaceConfig {
+	return &config.SquarespaceConfig{
+		BaseURL:     s.URL,
+		AccessToken: "sstest-token",
+		Environment: "test",
+	}
+}
+
+func (s *Server) AddProduct(product models.Product) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.products[product.ID] = product
+	for _, variant := range product.Products {
+		s.inventory[variant.ID] = variant.Stock
+	}
+}
+
+func (s *Server) AddOrder(order models.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders[order.ID] = order
+}
+
+func (s *Server) SetInventory(variantID string, stock models.ProductStock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inventory[variantID] = stock
+}
+
+func (s *Server) AddProfile(customerID string, profile models.Address) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles[customerID] = profile
+}
+
+func (s *Server) AddTransaction(transaction models.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transactions[transaction.ID] = transaction
+}
+
+// Orders returns every order the fake holds, including ones created through the API.
+func (s *Server) Orders() []models.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := make([]models.Order, 0, len(s.orders))
+	for _, id := range sortedKeys(s.orders) {
+		orders = append(orders, s.orders[id])
+	}
+	return orders
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// InjectFault installs f, replacing any previous fault.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = &f
+}
+
+func (s *Server) ClearFault() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = nil
+}
+
+// Requests returns the number of requests served so far.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	var fault *Fault
+	if s.fault != nil {
+		f := *s.fault
+		fault = &f
+		if s.fault.Count > 0 {
+			s.fault.Count--
+			if s.fault.Count == 0 {
+				s.fault = nil
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil {
+		if fault.RetryAfter != "" {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		writeError(w, fault.Status, "INJECTED_FAULT", "fault injected by sstest")
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "missing bearer token")
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, webhooksPath) {
+		s.serveWebhooks(w, r)
+		return
+	}
+
+	resource, id, ok := parsePath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "unknown endpoint "+r.URL.Path)
+		return
+	}
+
+	switch {
+	case resource == "products" && id == "" && r.Method == http.MethodGet:
+		s.listProducts(w, r)
+	case resource == "products" && r.Method == http.MethodGet:
+		s.getProduct(w, id)
+	case resource == "orders" && id == "" && r.Method == http.MethodGet:
+		s.listOrders(w, r)
+	case resource == "orders" && id == "" && r.Method == http.MethodPost:
+		s.createOrder(w, r)
+	case resource == "orders" && r.Method == http.MethodGet:
+		s.getOrder(w, id)
+	case resource == "inventory" && id != "" && r.Method == http.MethodGet:
+		s.getInventory(w, id)
+	case resource == "inventory" && id != "" && r.Method == http.MethodPatch:
+		s.updateInventory(w, r, id)
+	case resource == "profiles" && id != "" && r.Method == http.MethodGet:
+		s.getProfile(w, id)
+	case resource == "transactions" && id == "" && r.Method == http.MethodGet:
+		s.listTransactions(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", r.Method+" "+r.URL.Path)
+	}
+}
+
+// parsePath splits /1.0/commerce[/sites/{siteId}]/{resource}[/{id}].
+func parsePath(path string) (resource, id string, ok bool) {
+	rest, found := strings.CutPrefix(path, "/1.0/commerce/")
+	if !found {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "sites" {
+		parts = parts[2:]
+	}
+	switch len(parts) {
+	case 1:
+		return parts[0], "", parts[0] != ""
+	case 2:
+		return parts[0], parts[1], true
+	}
+	return "", "", false
+}
+
+func (s *Server) listProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	category, tag := query.Get("category"), query.Get("tag")
+
+	s.mu.Lock()
+	var products []models.Product
+	for _, id := range sortedKeys(s.products) {
+		product := s.withStockLocked(s.products[id])
+		if category != "" && !contains(product.Categories, category) {
+			continue
+		}
+		if tag != "" && !contains(product.Tags, tag) {
+			continue
+		}
+		products = append(products, product)
+	}
+	s.mu.Unlock()
+
+	page, pagination, err := paginate(products, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"result": page, "pagination": pagination})
+}
+
+func (s *Server) getProduct(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	product, ok := s.products[id]
+	product = s.withStockLocked(product)
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "product "+id+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, product)
+}
+
+func (s *Server) listOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	status, customerID := query.Get("status"), query.Get("customerId")
+
+	s.mu.Lock()
+	var orders []models.Order
+	for _, id := range sortedKeys(s.orders) {
+		order := s.orders[id]
+		if status != "" && order.Status != status {
+			continue
+		}
+		if customerID != "" && (order.CustomerID == nil || *order.CustomerID != customerID) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	s.mu.Unlock()
+
+	page, pagination, err := paginate(orders, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"result": page, "pagination": pagination})
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	order, ok := s.orders[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "order "+id+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
+	var order models.Order
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", err.Error())
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	s.mu.Lock()
+	s.nextOrder++
+	order.ID = fmt.Sprintf("order-%d", s.nextOrder)
+	order.OrderNumber = strconv.Itoa(s.nextOrder)
+	if order.Status == "" {
+		order.Status = "PENDING"
+	}
+	order.SystemData = models.SystemData{CreatedOn: now, ModifiedOn: now}
+	s.orders[order.ID] = order
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// listTransactions answers in the transactions envelope, with documents and
+// cursor-only pagination. Cursors carry the window they were issued for, as
+// upstream cursors do.
+func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	window := query.Get("modifiedAfter") + "|" + query.Get("modifiedBefore")
+	if cursor := query.Get("cursor"); cursor != "" {
+		offset, issued, _ := strings.Cut(cursor, "|")
+		query.Set("cursor", offset)
+		window = issued
+	}
+
+	after, before, err := parseWindow(window)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	var transactions []models.Transaction
+	for _, id := range sortedKeys(s.transactions) {
+		transaction := s.transactions[id]
+		if !after.IsZero() && (transaction.ModifiedOn.Before(after) || transaction.ModifiedOn.After(before)) {
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	s.mu.Unlock()
+
+	page, pagination, err := paginate(transactions, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", err.Error())
+		return
+	}
+
+	cursor := ""
+	if pagination.NextPage != nil {
+		cursor = *pagination.NextPage + "|" + window
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"documents": page,
+		"pagination": map[string]interface{}{
+			"hasNextPage":    cursor != "",
+			"nextPageCursor": cursor,
+		},
+	})
+}
+
+// parseWindow parses "after|before", where both bounds are RFC 3339
+// timestamps or both are empty.
+func parseWindow(window string) (after, before time.Time, err error) {
+	afterValue, beforeValue, _ := strings.Cut(window, "|")
+	if (afterValue == "") != (beforeValue == "") {
+		return after, before, fmt.Errorf("modifiedAfter and modifiedBefore must be given together")
+	}
+	if afterValue == "" {
+		return after, before, nil
+	}
+	if after, err = time.Parse(time.RFC3339, afterValue); err != nil {
+		return after, before, fmt.Errorf("invalid modifiedAfter %q", afterValue)
+	}
+	if before, err = time.Parse(time.RFC3339, beforeValue); err != nil {
+		return after, before, fmt.Errorf("invalid modifiedBefore %q", beforeValue)
+	}
+	return after, before, nil
+}
+
+// withStockLocked returns product with each variant's stock taken from the
+// inventory, so inventory updates show up in product reads as they do upstream.
+func (s *Server) withStockLocked(product models.Product) models.Product {
+	variants := make([]models.ProductVariant, len(product.Products))
+	for i, variant := range product.Products {
+		if stock, ok := s.inventory[variant.ID]; ok {
+			variant.Stock = stock
+		}
+		variants[i] = variant
+	}
+	product.Products = variants
+	return product
+}
+
+func (s *Server) getInventory(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	stock, ok := s.inventory[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "inventory for "+id+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, stock)
+}
+
+func (s *Server) updateInventory(w http.ResponseWriter, r *http.Request, id string) {
+	var payload struct {
+		Quantity *int `json:"quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Quantity == nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", "quantity is required")
+		return
+	}
+
+	s.mu.Lock()
+	stock, ok := s.inventory[id]
+	if ok {
+		quantity := *payload.Quantity
+		stock.Quantity = &quantity
+		stock.TrackInventory = true
+		s.inventory[id] = stock
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "inventory for "+id+" not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getProfile(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	profile, ok := s.profiles[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "profile "+id+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// paginate applies limit/offset or an opaque cursor (the next offset) to items.
+func paginate[T any](items []T, query map[string][]string) ([]T, *models.Pagination, error) {
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	limit, offset := 50, 0
+	if value := get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, nil, fmt.Errorf("invalid limit %q", value)
+		}
+		limit = n
+	}
+	if value := get("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, nil, fmt.Errorf("invalid offset %q", value)
+		}
+		offset = n
+	}
+	if value := get("cursor"); value != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(value, "c"))
+		if err != nil || n < 0 {
+			return nil, nil, fmt.Errorf("invalid cursor %q", value)
+		}
+		offset = n
+	}
+
+	total := len(items)
+	pagination := &models.Pagination{TotalResults: &total}
+	if offset >= total {
+		return []T{}, pagination, nil
+	}
+	end := offset + limit
+	if end < total {
+		next := "c" + strconv.Itoa(end)
+		pagination.NextPage = &next
+	} else {
+		end = total
+	}
+	return items[offset:end], pagination, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	writeJSON(w, status, map[string]string{
+		"type":      errorType,
+		"message":   message,
+		"contextId": fmt.Sprintf("sstest-%d", time.Now().UnixNano()),
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
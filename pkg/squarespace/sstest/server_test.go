@@ -0,0 +1,144 @@
+package sstest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+func newClient(t *testing.T) (*sstest.Server, *squarespace.Client) {
+	t.Helper()
+	server := sstest.NewServer()
+	t.Cleanup(server.Close)
+	server.SeedDemoData()
+	return server, squarespace.NewClient(server.Config())
+}
+
+func TestClientPaginatesProducts(t *testing.T) {
+	_, client := newClient(t)
+	ctx := context.Background()
+
+	page, pagination, err := client.GetProducts(ctx, squarespace.WithProductLimit(2))
+	if err != nil {
+		t.Fatalf("GetProducts() error = %v", err)
+	}
+	if len(page) != 2 {
+		t.Fatalf("first page has %d products, want 2", len(page))
+	}
+	if pagination == nil || pagination.NextPage == nil {
+		t.Fatal("first page has no next cursor")
+	}
+
+	rest, pagination, err := client.GetProducts(ctx, squarespace.WithProductLimit(2), squarespace.WithProductCursor(*pagination.NextPage))
+	if err != nil {
+		t.Fatalf("GetProducts(cursor) error = %v", err)
+	}
+	if len(rest) != 1 || pagination.NextPage != nil {
+		t.Fatalf("last page = %d products, next %v; want 1 and no cursor", len(rest), pagination.NextPage)
+	}
+
+	var ids []string
+	it := client.ProductsIter(ctx, squarespace.WithProductLimit(1))
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("ProductsIter() error = %v", err)
+	}
+	want := []string{"prod-ebook", "prod-print", "prod-tee"}
+	if len(ids) != len(want) {
+		t.Fatalf("ProductsIter() = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ProductsIter() = %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestClientMapsUpstreamErrors(t *testing.T) {
+	server, client := newClient(t)
+	ctx := context.Background()
+
+	if _, err := client.GetProduct(ctx, "missing"); !errors.Is(err, squarespace.ErrNotFound) {
+		t.Errorf("GetProduct(missing) error = %v, want ErrNotFound", err)
+	}
+
+	server.InjectFault(sstest.Fault{Status: http.StatusTooManyRequests, Count: 1})
+	if _, err := client.GetProduct(ctx, "prod-print"); !errors.Is(err, squarespace.ErrRateLimited) {
+		t.Errorf("GetProduct() under 429 error = %v, want ErrRateLimited", err)
+	}
+
+	server.InjectFault(sstest.Fault{Status: http.StatusBadGateway})
+	_, err := client.GetOrder(ctx, "order-1000")
+	var apiErr *squarespace.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.Type != "INJECTED_FAULT" {
+		t.Errorf("GetOrder() under 502 error = %v, want INJECTED_FAULT 502", err)
+	}
+	server.ClearFault()
+
+	cfg := server.Config()
+	cfg.AccessToken = ""
+	if _, err := squarespace.NewClient(cfg).GetProduct(ctx, "prod-print"); !errors.Is(err, squarespace.ErrUnauthorized) {
+		t.Errorf("GetProduct() without a token error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestClientCreatesAndListsOrders(t *testing.T) {
+	server, client := newClient(t)
+	ctx := context.Background()
+
+	customerID := "customer-2"
+	created, err := client.CreateOrder(ctx, &models.Order{
+		CustomerID: &customerID,
+		Email:      "grace@example.com",
+		LineItems: []models.OrderLineItem{{
+			ProductID:  "prod-ebook",
+			Quantity:   1,
+			UnitPrice:  models.Money{Value: "12.00", Currency: "USD"},
+			TotalPrice: models.Money{Value: "12.00", Currency: "USD"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	if created.ID != "order-1001" || created.Status != "PENDING" {
+		t.Errorf("CreateOrder() = %s %s, want order-1001 PENDING", created.ID, created.Status)
+	}
+
+	fetched, err := client.GetOrder(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetOrder() error = %v", err)
+	}
+	if fetched.Email != "grace@example.com" {
+		t.Errorf("GetOrder() email = %q, want grace@example.com", fetched.Email)
+	}
+
+	orders, pagination, err := client.GetOrders(ctx, squarespace.WithOrderCustomerID(customerID))
+	if err != nil {
+		t.Fatalf("GetOrders() error = %v", err)
+	}
+	if len(orders) != 1 || orders[0].ID != created.ID {
+		t.Errorf("GetOrders(customer-2) = %v, want just %s", orders, created.ID)
+	}
+	if pagination.TotalResults == nil || *pagination.TotalResults != 1 {
+		t.Errorf("GetOrders() total = %v, want 1", pagination.TotalResults)
+	}
+
+	var ids []string
+	it := client.OrdersIter(ctx, squarespace.WithOrderLimit(1))
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("OrdersIter() error = %v", err)
+	}
+	if len(ids) != len(server.Orders()) {
+		t.Errorf("OrdersIter() = %v, want all %d orders", ids, len(server.Orders()))
+	}
+}
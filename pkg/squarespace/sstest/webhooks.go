@@ -0,0 +1,252 @@
+package sstest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+const webhooksPath = "/1.0/webhooks/subscriptions"
+
+// Subscriptions returns every webhook subscription the fake holds, secrets
+// included.
+func (s *Server) Subscriptions() []models.WebhookSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscriptions := make([]models.WebhookSubscription, 0, len(s.subscriptions))
+	for _, id := range sortedKeys(s.subscriptions) {
+		subscriptions = append(subscriptions, s.subscriptions[id])
+	}
+	return subscriptions
+}
+
+// Notify delivers a signed notification for topic to every subscription
+// listening for it, as Squarespace would after a store event.
+func (s *Server) Notify(topic string, data interface{}) error {
+	for _, subscription := range s.Subscriptions() {
+		if !contains(subscription.Topics, topic) {
+			continue
+		}
+		status, err := s.deliver(subscription, topic, data)
+		if err != nil {
+			return err
+		}
+		if status >= 300 {
+			return fmt.Errorf("sstest: %s answered %s notification with status %d", subscription.EndpointURL, topic, status)
+		}
+	}
+	return nil
+}
+
+// serveWebhooks handles /1.0/webhooks/subscriptions[/{id}[/actions/{action}]].
+func (s *Server) serveWebhooks(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, webhooksPath), "/"), "/")
+	id := parts[0]
+
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		s.listSubscriptions(w)
+	case id == "" && r.Method == http.MethodPost:
+		s.createSubscription(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.withSubscription(w, id, func(subscription models.WebhookSubscription) {
+			subscription.Secret = ""
+			writeJSON(w, http.StatusOK, subscription)
+		})
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		s.updateSubscription(w, r, id)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		s.deleteSubscription(w, id)
+	case len(parts) == 3 && parts[1] == "actions" && parts[2] == "rotateSecret" && r.Method == http.MethodPost:
+		s.rotateSecret(w, id)
+	case len(parts) == 3 && parts[1] == "actions" && parts[2] == "sendTestNotification" && r.Method == http.MethodPost:
+		s.sendTestNotification(w, r, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", r.Method+" "+r.URL.Path)
+	}
+}
+
+type subscriptionPayload struct {
+	EndpointURL string   `json:"endpointUrl"`
+	Topics      []string `json:"topics"`
+}
+
+func (s *Server) listSubscriptions(w http.ResponseWriter) {
+	subscriptions := s.Subscriptions()
+	// Squarespace only reveals secrets on create and rotate
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"webhookSubscriptions": subscriptions})
+}
+
+func (s *Server) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var payload subscriptionPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.EndpointURL == "" || len(payload.Topics) == 0 {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", "endpointUrl and topics are required")
+		return
+	}
+
+	now := time.Now().UTC()
+	s.mu.Lock()
+	s.nextSubscription++
+	subscription := models.WebhookSubscription{
+		ID:          fmt.Sprintf("sub-%d", s.nextSubscription),
+		EndpointURL: payload.EndpointURL,
+		Topics:      payload.Topics,
+		Secret:      newSecret(),
+		CreatedOn:   now,
+		UpdatedOn:   now,
+	}
+	s.subscriptions[subscription.ID] = subscription
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, subscription)
+}
+
+func (s *Server) updateSubscription(w http.ResponseWriter, r *http.Request, id string) {
+	var payload subscriptionPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", "invalid subscription update")
+		return
+	}
+
+	s.mu.Lock()
+	subscription, ok := s.subscriptions[id]
+	if ok {
+		if payload.EndpointURL != "" {
+			subscription.EndpointURL = payload.EndpointURL
+		}
+		if payload.Topics != nil {
+			subscription.Topics = payload.Topics
+		}
+		subscription.UpdatedOn = time.Now().UTC()
+		s.subscriptions[id] = subscription
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "subscription "+id+" not found")
+		return
+	}
+	subscription.Secret = ""
+	writeJSON(w, http.StatusOK, subscription)
+}
+
+func (s *Server) deleteSubscription(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	_, ok := s.subscriptions[id]
+	delete(s.subscriptions, id)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "subscription "+id+" not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) rotateSecret(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	subscription, ok := s.subscriptions[id]
+	if ok {
+		subscription.Secret = newSecret()
+		subscription.UpdatedOn = time.Now().UTC()
+		s.subscriptions[id] = subscription
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "subscription "+id+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"secret": subscription.Secret})
+}
+
+func (s *Server) sendTestNotification(w http.ResponseWriter, r *http.Request, id string) {
+	var payload struct {
+		Topic string `json:"topic"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Topic == "" {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST_ERROR", "topic is required")
+		return
+	}
+
+	s.withSubscription(w, id, func(subscription models.WebhookSubscription) {
+		status, err := s.deliver(subscription, payload.Topic, map[string]string{})
+		if err != nil {
+			writeError(w, http.StatusBadGateway, "DELIVERY_FAILED", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"statusCode": status})
+	})
+}
+
+func (s *Server) withSubscription(w http.ResponseWriter, id string, fn func(models.WebhookSubscription)) {
+	s.mu.Lock()
+	subscription, ok := s.subscriptions[id]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "subscription "+id+" not found")
+		return
+	}
+	fn(subscription)
+}
+
+// deliver posts a notification signed with the subscription's secret and
+// returns the status the endpoint answered with.
+func (s *Server) deliver(subscription models.WebhookSubscription, topic string, data interface{}) (int, error) {
+	s.mu.Lock()
+	s.nextNotification++
+	notificationID := fmt.Sprintf("notification-%d", s.nextNotification)
+	s.mu.Unlock()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":             notificationID,
+		"websiteId":      "sstest-website",
+		"subscriptionId": subscription.ID,
+		"topic":          topic,
+		"createdOn":      time.Now().UTC(),
+		"data":           data,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	key, _ := hex.DecodeString(subscription.Secret)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+
+	req, err := http.NewRequest(http.MethodPost, subscription.EndpointURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Squarespace-Signature", hex.EncodeToString(mac.Sum(nil)))
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func newSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(secret)
+}
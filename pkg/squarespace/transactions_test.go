@@ -0,0 +1,109 @@
+package squarespace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/birddigital/store.adrienbird.net/internal/config"
+)
+
+func TestGetTransactions(t *testing.T) {
+	after := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	before := after.Add(24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		siteID     string
+		options    []TransactionOption
+		response   string
+		wantPath   string
+		wantQuery  url.Values
+		wantIDs    []string
+		wantCursor string
+	}{
+		{
+			name:     "window",
+			options:  []TransactionOption{WithTransactionWindow(after, before)},
+			response: `{"documents":[{"id":"txn-1"},{"id":"txn-2"}],"pagination":{"hasNextPage":true,"nextPageCursor":"c2"}}`,
+			wantPath: "/1.0/commerce/transactions",
+			wantQuery: url.Values{
+				"modifiedAfter":  {"2026-09-01T00:00:00Z"},
+				"modifiedBefore": {"2026-09-02T00:00:00Z"},
+			},
+			wantIDs:    []string{"txn-1", "txn-2"},
+			wantCursor: "c2",
+		},
+		{
+			name:      "cursor drops the window",
+			options:   []TransactionOption{WithTransactionWindow(after, before), WithTransactionCursor("c2")},
+			response:  `{"documents":[{"id":"txn-3"}],"pagination":{"hasNextPage":false,"nextPageCursor":null}}`,
+			wantPath:  "/1.0/commerce/transactions",
+			wantQuery: url.Values{"cursor": {"c2"}},
+			wantIDs:   []string{"txn-3"},
+		},
+		{
+			name:      "next page without a cursor",
+			response:  `{"documents":[],"pagination":{"hasNextPage":true,"nextPageCursor":""}}`,
+			wantPath:  "/1.0/commerce/transactions",
+			wantQuery: url.Values{},
+		},
+		{
+			name:      "configured site",
+			siteID:    "site-1",
+			response:  `{"documents":[]}`,
+			wantPath:  "/1.0/commerce/sites/site-1/transactions",
+			wantQuery: url.Values{},
+		},
+		{
+			name:      "site option overrides the configured site",
+			siteID:    "site-1",
+			options:   []TransactionOption{WithTransactionSiteID("site-2")},
+			response:  `{"documents":[]}`,
+			wantPath:  "/1.0/commerce/sites/site-2/transactions",
+			wantQuery: url.Values{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Clone(context.Background())
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+			client := NewClient(&config.SquarespaceConfig{BaseURL: server.URL, SiteID: tt.siteID, RequestTimeout: 5 * time.Second})
+
+			transactions, pagination, err := client.GetTransactions(context.Background(), tt.options...)
+			if err != nil {
+				t.Fatalf("GetTransactions() error = %v", err)
+			}
+			if got.URL.Path != tt.wantPath {
+				t.Errorf("path = %s, want %s", got.URL.Path, tt.wantPath)
+			}
+			if query := got.URL.Query(); query.Encode() != tt.wantQuery.Encode() {
+				t.Errorf("query = %v, want %v", query, tt.wantQuery)
+			}
+
+			var ids []string
+			for _, transaction := range transactions {
+				ids = append(ids, transaction.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("transactions = %v, want %v", ids, tt.wantIDs)
+			}
+			switch {
+			case tt.wantCursor == "" && pagination.NextPage != nil:
+				t.Errorf("next page = %q, want none", *pagination.NextPage)
+			case tt.wantCursor != "" && (pagination.NextPage == nil || *pagination.NextPage != tt.wantCursor):
+				t.Errorf("next page = %v, want %q", pagination.NextPage, tt.wantCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package validation
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// postalCodePatterns covers the countries we ship to most; others only need
+// a non-empty postal code.
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"CA": regexp.MustCompile(`^[A-Za-z]\d[A-Za-z][ -]?\d[A-Za-z]\d$`),
+	"GB": regexp.MustCompile(`^[A-Za-z]{1,2}\d[A-Za-z\d]? ?\d[A-Za-z]{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Za-z]{2}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+}
+
+// regions lists the valid state or province codes for countries that require one.
+var regions = map[string][]string{
+	"US": {
+		"AL", "AK", "AZ", "AR", "CA", "CO", "CT", "DE", "DC", "FL", "GA", "HI", "ID", "IL", "IN", "IA",
+		"KS", "KY", "LA", "ME", "MD", "MA", "MI", "MN", "MS", "MO", "MT", "NE", "NV", "NH", "NJ", "NM",
+		"NY", "NC", "ND", "OH", "OK", "OR", "PA", "RI", "SC", "SD", "TN", "TX", "UT", "VT", "VA", "WA",
+		"WV", "WI", "WY", "AS", "GU", "MP", "PR", "VI", "AA", "AE", "AP",
+	},
+	"CA": {"AB", "BC", "MB", "NB", "NL", "NS", "NT", "NU", "ON", "PE", "QC", "SK", "YT"},
+}
+
+// ValidateEmail checks that email is a single bare address with a dotted domain.
+func ValidateEmail(email string) Errors {
+	var errs Errors
+	validateEmail(&errs, "email", email)
+	return errs
+}
+
+func validateEmail(errs *Errors, field, email string) {
+	if strings.TrimSpace(email) == "" {
+		errs.add(field, "required", "email is required")
+		return
+	}
+	parsed, err := mail.ParseAddress(email)
+	if err != nil || parsed.Address != email || parsed.Name != "" {
+		errs.add(field, "invalid_format", "%q is not a valid email address", email)
+		return
+	}
+	_, domain, _ := strings.Cut(email, "@")
+	if !strings.Contains(domain, ".") || strings.HasSuffix(domain, ".") {
+		errs.add(field, "invalid_format", "%q is not a valid email address", email)
+	}
+}
+
+// ValidateAddress checks an address is complete for its country. field
+// prefixes the reported paths, e.g. "billingAddress".
+func ValidateAddress(field string, address models.Address) Errors {
+	var errs Errors
+	validateAddress(&errs, field, address)
+	return errs
+}
+
+func validateAddress(errs *Errors, field string, address models.Address) {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"firstName", address.FirstName},
+		{"lastName", address.LastName},
+		{"addressLine1", address.AddressLine1},
+		{"city", address.City},
+		{"country", address.Country},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			errs.add(field+"."+r.name, "required", "%s is required", r.name)
+		}
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(address.Country))
+	if country != "" && !countryCodePattern.MatchString(country) {
+		errs.add(field+".country", "invalid_format", "country must be an ISO 3166-1 alpha-2 code")
+		return
+	}
+
+	postalCode := strings.TrimSpace(address.PostalCode)
+	if postalCode == "" {
+		errs.add(field+".postalCode", "required", "postalCode is required")
+	} else if pattern, ok := postalCodePatterns[country]; ok && !pattern.MatchString(postalCode) {
+		errs.add(field+".postalCode", "invalid_format", "%q is not a valid postal code for %s", postalCode, country)
+	}
+
+	if codes, ok := regions[country]; ok {
+		state := ""
+		if address.State != nil {
+			state = strings.ToUpper(strings.TrimSpace(*address.State))
+		}
+		switch {
+		case state == "":
+			errs.add(field+".state", "required", "state is required for %s addresses", country)
+		case !containsString(codes, state):
+			errs.add(field+".state", "invalid_value", "%q is not a valid state or province code for %s", state, country)
+		}
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+// ProductLookup fetches a product, e.g. squarespace.API.GetProduct.
+type ProductLookup func(ctx context.Context, productID string) (*models.Product, error)
+
+// ValidateCustomizations checks submitted customizations against a product's
+// custom form. Customizations match form fields by field ID or label. field
+// prefixes the reported paths.
+func ValidateCustomizations(field string, form *models.CustomForm, customizations []models.OrderCustomization) Errors {
+	var errs Errors
+
+	var formFields []models.CustomFormField
+	if form != nil {
+		formFields = form.Fields
+	}
+
+	submitted := make(map[int]string, len(customizations))
+	for i, customization := range customizations {
+		path := fmt.Sprintf("%s[%d]", field, i)
+		index, ok := findFormField(formFields, customization.FieldName)
+		if !ok {
+			errs.add(path+".fieldName", "unknown_field", "%q is not a field of this product's form", customization.FieldName)
+			continue
+		}
+		if _, seen := submitted[index]; seen {
+			errs.add(path+".fieldName", "duplicate_field", "%q was submitted more than once", customization.FieldName)
+			continue
+		}
+		submitted[index] = customization.Value
+		validateFormValue(&errs, path+".value", formFields[index], customization.Value)
+	}
+
+	for i, formField := range formFields {
+		if value, ok := submitted[i]; formField.Required && (!ok || strings.TrimSpace(value) == "") {
+			errs.add(field, "required", "%q is required", formField.Label)
+		}
+	}
+
+	return errs
+}
+
+// ValidateOrderCustomizations validates every line item's customizations
+// against its product's form, fetching each product once. Products the
+// catalog doesn't have are reported as field errors; other lookup errors are
+// returned as-is so callers can tell upstream failures from field errors.
+func ValidateOrderCustomizations(ctx context.Context, lineItems []models.OrderLineItem, lookup ProductLookup) (Errors, error) {
+	var errs Errors
+	forms := make(map[string]*models.CustomForm)
+	unknown := make(map[string]bool)
+	for i, item := range lineItems {
+		form, ok := forms[item.ProductID]
+		if !ok && !unknown[item.ProductID] {
+			product, err := lookup(ctx, item.ProductID)
+			switch {
+			case errors.Is(err, squarespace.ErrNotFound):
+				unknown[item.ProductID] = true
+			case err != nil:
+				return nil, err
+			default:
+				form = product.CustomForm
+				forms[item.ProductID] = form
+			}
+		}
+		if unknown[item.ProductID] {
+			errs.add(fmt.Sprintf("lineItems[%d].productId", i), "unknown_product", "product %q does not exist", item.ProductID)
+			continue
+		}
+		errs = append(errs, ValidateCustomizations(fmt.Sprintf("lineItems[%d].customizations", i), form, item.Customizations)...)
+	}
+	return errs, nil
+}
+
+func findFormField(fields []models.CustomFormField, name string) (int, bool) {
+	for i, formField := range fields {
+		if formField.FieldID == name {
+			return i, true
+		}
+	}
+	for i, formField := range fields {
+		if strings.EqualFold(formField.Label, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func validateFormValue(errs *Errors, path string, formField models.CustomFormField, value string) {
+	if value == "" {
+		return
+	}
+
+	if len(formField.Choices) > 0 && !containsString(formField.Choices, value) {
+		errs.add(path, "invalid_choice", "%q is not one of the allowed choices for %q", value, formField.Label)
+		return
+	}
+
+	rules := formField.Validation
+	if rules == nil {
+		return
+	}
+	length := utf8.RuneCountInString(value)
+	if rules.MinLength != nil && length < *rules.MinLength {
+		errs.add(path, "too_short", "%q must be at least %d characters", formField.Label, *rules.MinLength)
+	}
+	if rules.MaxLength != nil && length > *rules.MaxLength {
+		errs.add(path, "too_long", "%q must be at most %d characters", formField.Label, *rules.MaxLength)
+	}
+	if rules.Pattern != "" {
+		// A broken pattern is a catalog problem, not the shopper's
+		pattern, err := regexp.Compile(`^(?:` + rules.Pattern + `)$`)
+		if err == nil && !pattern.MatchString(value) {
+			errs.add(path, "pattern_mismatch", "%q is not in the expected format", formField.Label)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+func intPtr(n int) *int { return &n }
+
+var engravingForm = &models.CustomForm{
+	FormID: "form-1",
+	Fields: []models.CustomFormField{
+		{FieldID: "engraving", Label: "Engraving", Required: true, Validation: &models.Validation{MinLength: intPtr(2), MaxLength: intPtr(10)}},
+		{FieldID: "finish", Label: "Finish", Choices: []string{"Matte", "Gloss"}},
+		{FieldID: "code", Label: "Gift code", Validation: &models.Validation{Pattern: `[A-Z]{3}-\d{3}`}},
+		{FieldID: "broken", Label: "Broken", Validation: &models.Validation{Pattern: `(`}},
+	},
+}
+
+func TestValidateCustomizations(t *testing.T) {
+	tests := []struct {
+		name           string
+		form           *models.CustomForm
+		customizations []models.OrderCustomization
+		want           []string // "field code" pairs
+	}{
+		{"valid by id", engravingForm, []models.OrderCustomization{
+			{FieldName: "engraving", Value: "Ada"},
+			{FieldName: "finish", Value: "Matte"},
+			{FieldName: "code", Value: "ABC-123"},
+		}, nil},
+		{"label matches case-insensitively", engravingForm, []models.OrderCustomization{{FieldName: "ENGRAVING", Value: "Ada"}}, nil},
+		{"unknown field", engravingForm, []models.OrderCustomization{
+			{FieldName: "engraving", Value: "Ada"},
+			{FieldName: "colour", Value: "red"},
+		}, []string{"items[1].fieldName unknown_field"}},
+		{"no form", nil, []models.OrderCustomization{{FieldName: "engraving", Value: "Ada"}}, []string{"items[0].fieldName unknown_field"}},
+		{"duplicate field", engravingForm, []models.OrderCustomization{
+			{FieldName: "engraving", Value: "Ada"},
+			{FieldName: "Engraving", Value: "Bob"},
+		}, []string{"items[1].fieldName duplicate_field"}},
+		{"invalid choice", engravingForm, []models.OrderCustomization{
+			{FieldName: "engraving", Value: "Ada"},
+			{FieldName: "finish", Value: "Satin"},
+		}, []string{"items[1].value invalid_choice"}},
+		{"too short", engravingForm, []models.OrderCustomization{{FieldName: "engraving", Value: "A"}}, []string{"items[0].value too_short"}},
+		{"too long in runes", engravingForm, []models.OrderCustomization{{FieldName: "engraving", Value: "ééééééééééé"}}, []string{"items[0].value too_long"}},
+		{"multibyte within max", engravingForm, []models.OrderCustomization{{FieldName: "engraving", Value: "éééééééééé"}}, nil},
+		{"pattern must match whole value", engravingForm, []models.OrderCustomization{
+			{FieldName: "engraving", Value: "Ada"},
+			{FieldName: "code", Value: "ABC-1234"},
+		}, []string{"items[1].value pattern_mismatch"}},
+		{"broken pattern is ignored", engravingForm, []models.OrderCustomization{
+			{FieldName: "engraving", Value: "Ada"},
+			{FieldName: "broken", Value: "anything"},
+		}, nil},
+		{"required missing", engravingForm, []models.OrderCustomization{{FieldName: "finish", Value: "Gloss"}}, []string{"items required"}},
+		{"required blank", engravingForm, []models.OrderCustomization{{FieldName: "engraving", Value: "   "}}, []string{"items required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, fieldErr := range ValidateCustomizations("items", tt.form, tt.customizations) {
+				got = append(got, fieldErr.Field+" "+fieldErr.Code)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ValidateCustomizations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOrderCustomizations(t *testing.T) {
+	errUpstream := errors.New("upstream down")
+	lookups := make(map[string]int)
+	lookup := func(_ context.Context, productID string) (*models.Product, error) {
+		lookups[productID]++
+		switch productID {
+		case "prod-engraved":
+			return &models.Product{ID: productID, CustomForm: engravingForm}, nil
+		case "prod-plain":
+			return &models.Product{ID: productID}, nil
+		case "prod-flaky":
+			return nil, errUpstream
+		}
+		return nil, fmt.Errorf("get product %s: %w", productID, squarespace.ErrNotFound)
+	}
+
+	lineItems := []models.OrderLineItem{
+		{ProductID: "prod-engraved", Customizations: []models.OrderCustomization{{FieldName: "engraving", Value: "Ada"}}},
+		{ProductID: "prod-missing"},
+		{ProductID: "prod-engraved"},
+		{ProductID: "prod-missing"},
+		{ProductID: "prod-plain"},
+	}
+	errs, err := ValidateOrderCustomizations(context.Background(), lineItems, lookup)
+	if err != nil {
+		t.Fatalf("ValidateOrderCustomizations() error = %v", err)
+	}
+	var got []string
+	for _, fieldErr := range errs {
+		got = append(got, fieldErr.Field+" "+fieldErr.Code)
+	}
+	want := []string{
+		"lineItems[1].productId unknown_product",
+		"lineItems[2].customizations required",
+		"lineItems[3].productId unknown_product",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ValidateOrderCustomizations() = %v, want %v", got, want)
+	}
+	for productID, n := range lookups {
+		if n != 1 {
+			t.Errorf("looked up %s %d times, want once", productID, n)
+		}
+	}
+
+	_, err = ValidateOrderCustomizations(context.Background(), []models.OrderLineItem{{ProductID: "prod-flaky"}}, lookup)
+	if !errors.Is(err, errUpstream) {
+		t.Errorf("ValidateOrderCustomizations() error = %v, want the upstream failure", err)
+	}
+}
@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/money"
+)
+
+// ValidateOrder checks contact details, addresses, line items and totals,
+// returning every field error found.
+func ValidateOrder(order *models.Order) Errors {
+	var errs Errors
+
+	validateEmail(&errs, "email", order.Email)
+	validateAddress(&errs, "billingAddress", order.BillingAddress)
+	if order.ShippingAddress != nil {
+		validateAddress(&errs, "shippingAddress", *order.ShippingAddress)
+	}
+
+	// The first priced amount sets the currency everything else must match
+	currency := ""
+	checkMoney := func(field string, amount models.Money) (money.Money, bool) {
+		if amount.Currency == "" {
+			errs.add(field+".currency", "required", "currency is required")
+			return money.Money{}, false
+		}
+		if currency == "" {
+			currency = amount.Currency
+		} else if amount.Currency != currency {
+			errs.add(field+".currency", "currency_mismatch", "currency %s does not match order currency %s", amount.Currency, currency)
+		}
+		parsed, err := money.FromModel(amount)
+		if errors.Is(err, money.ErrInvalidCurrency) {
+			errs.add(field+".currency", "invalid_format", "%q is not an ISO 4217 currency code", amount.Currency)
+			return money.Money{}, false
+		}
+		if err != nil {
+			errs.add(field+".value", "invalid_format", "%q is not a valid %s amount", amount.Value, amount.Currency)
+			return money.Money{}, false
+		}
+		if parsed.IsNegative() {
+			errs.add(field+".value", "invalid_value", "amount must not be negative")
+			return money.Money{}, false
+		}
+		return parsed, true
+	}
+
+	if len(order.LineItems) == 0 {
+		errs.add("lineItems", "required", "at least one line item is required")
+	}
+	lineTotals, linesOK := make([]money.Money, 0, len(order.LineItems)), true
+	for i, item := range order.LineItems {
+		field := fmt.Sprintf("lineItems[%d]", i)
+		if item.ProductID == "" {
+			errs.add(field+".productId", "required", "productId is required")
+		}
+		if item.Quantity <= 0 {
+			errs.add(field+".quantity", "invalid_value", "quantity must be positive")
+		}
+
+		unit, unitOK := checkMoney(field+".unitPrice", item.UnitPrice)
+		total, totalOK := checkMoney(field+".totalPrice", item.TotalPrice)
+		if unitOK && totalOK && item.Quantity > 0 && unit.Currency() == total.Currency() {
+			expected, err := unit.Mul(int64(item.Quantity))
+			if err != nil {
+				errs.add(field+".quantity", "invalid_value", "unitPrice %s x quantity %d is too large", item.UnitPrice.Value, item.Quantity)
+			} else if !expected.Equal(total) {
+				errs.add(field+".totalPrice", "total_mismatch", "totalPrice %s does not equal unitPrice %s x quantity %d", item.TotalPrice.Value, item.UnitPrice.Value, item.Quantity)
+			}
+		}
+		lineTotals = append(lineTotals, total)
+		linesOK = linesOK && totalOK
+	}
+
+	subtotal, subtotalOK := checkMoney("totals.subtotal", order.Totals.Subtotal)
+	tax, taxOK := checkMoney("totals.tax", order.Totals.Tax)
+	shipping, shippingOK := checkMoney("totals.shipping", order.Totals.Shipping)
+	discount, discountOK := checkMoney("totals.discount", order.Totals.Discount)
+	total, totalOK := checkMoney("totals.total", order.Totals.Total)
+
+	// Totals are only reconciled once every amount involved parsed; currency
+	// mismatches have been reported above
+	if linesOK && subtotalOK && len(lineTotals) > 0 {
+		if sum, err := money.Sum(lineTotals...); err == nil && !sum.Equal(subtotal) {
+			errs.add("totals.subtotal", "total_mismatch", "subtotal %s does not equal the sum of line totals %s", subtotal.Value(), sum.Value())
+		}
+	}
+	if subtotalOK && taxOK && shippingOK && discountOK && totalOK {
+		expected, err := money.Sum(subtotal, shipping, tax)
+		if err == nil {
+			expected, err = expected.Sub(discount)
+		}
+		if err == nil && !expected.Equal(total) {
+			errs.add("totals.total", "total_mismatch", "total %s does not equal subtotal + shipping + tax - discount = %s", total.Value(), expected.Value())
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+)
+
+func usd(value string) models.Money {
+	return models.Money{Value: value, Currency: "USD"}
+}
+
+func validOrder() *models.Order {
+	state := "OR"
+	return &models.Order{
+		Email: "ada@example.com",
+		BillingAddress: models.Address{
+			FirstName:    "Ada",
+			LastName:     "Lovelace",
+			AddressLine1: "12 Analytical Way",
+			City:         "Portland",
+			State:        &state,
+			PostalCode:   "97201",
+			Country:      "US",
+		},
+		LineItems: []models.OrderLineItem{
+			{ProductID: "prod-print", Quantity: 2, UnitPrice: usd("35.00"), TotalPrice: usd("70.00")},
+			{ProductID: "prod-ebook", Quantity: 1, UnitPrice: usd("12.00"), TotalPrice: usd("12.00")},
+		},
+		Totals: models.OrderTotals{
+			Subtotal: usd("82.00"),
+			Shipping: usd("5.00"),
+			Tax:      usd("6.56"),
+			Discount: usd("10.00"),
+			Total:    usd("83.56"),
+		},
+	}
+}
+
+func TestValidateOrderTotals(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*models.Order)
+		fields []string
+	}{
+		{"consistent", func(*models.Order) {}, nil},
+		{"subtotal off by a cent", func(o *models.Order) {
+			o.Totals.Subtotal = usd("82.01")
+			o.Totals.Total = usd("83.57")
+		}, []string{"totals.subtotal"}},
+		{"total ignores discount", func(o *models.Order) { o.Totals.Total = usd("93.56") }, []string{"totals.total"}},
+		{"both wrong", func(o *models.Order) { o.Totals.Subtotal = usd("80.00") }, []string{"totals.subtotal", "totals.total"}},
+		{"line total wrong", func(o *models.Order) { o.LineItems[0].TotalPrice = usd("60.00") }, []string{"lineItems[0].totalPrice", "totals.subtotal"}},
+		{"unparseable amount skips reconciliation", func(o *models.Order) { o.Totals.Tax = usd("6.5x") }, []string{"totals.tax.value"}},
+		{"currency mismatch skips reconciliation", func(o *models.Order) {
+			o.Totals.Shipping = models.Money{Value: "5.00", Currency: "EUR"}
+		}, []string{"totals.shipping.currency"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.modify(order)
+			errs := ValidateOrder(order)
+
+			var fields []string
+			for _, fieldErr := range errs {
+				fields = append(fields, fieldErr.Field)
+			}
+			if len(fields) != len(tt.fields) {
+				t.Fatalf("ValidateOrder() fields = %v, want %v (%v)", fields, tt.fields, errs)
+			}
+			for i := range fields {
+				if fields[i] != tt.fields[i] {
+					t.Fatalf("ValidateOrder() fields = %v, want %v (%v)", fields, tt.fields, errs)
+				}
+			}
+		})
+	}
+}
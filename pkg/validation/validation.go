@@ -0,0 +1,39 @@
+// Package validation checks orders server-side before they reach
+// Squarespace, collecting every problem rather than stopping at the first.
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes one invalid field. Field is a JSON path such as
+// "lineItems[0].quantity".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors is returned when validation fails; it is never empty.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Err returns e as an error, or nil when there are no field errors.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *Errors) add(field, code, format string, args ...interface{}) {
+	*e = append(*e, FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
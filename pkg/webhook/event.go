@@ -0,0 +1,47 @@
+// Package webhook receives Squarespace webhook notifications, verifying
+// their signatures and dispatching them to handlers by topic.
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Topic names a kind of Squarespace notification.
+type Topic string
+
+const (
+	TopicOrderCreate        Topic = "order.create"
+	TopicOrderUpdate        Topic = "order.update"
+	TopicExtensionUninstall Topic = "extension.uninstall"
+)
+
+// Notification is the envelope Squarespace posts for every topic.
+type Notification struct {
+	ID             string          `json:"id"`
+	WebsiteID      string          `json:"websiteId"`
+	SubscriptionID string          `json:"subscriptionId"`
+	Topic          Topic           `json:"topic"`
+	CreatedOn      time.Time       `json:"createdOn"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// OrderCreated is the data of an order.create notification.
+type OrderCreated struct {
+	Notification `json:"-"`
+	OrderID      string `json:"orderId"`
+}
+
+// OrderUpdated is the data of an order.update notification. Update says
+// what changed, e.g. "FULFILLED" or "CANCELED".
+type OrderUpdated struct {
+	Notification `json:"-"`
+	OrderID      string `json:"orderId"`
+	Update       string `json:"update"`
+}
+
+// ExtensionUninstalled is sent when the site owner disconnects this
+// extension; the notification carries no data.
+type ExtensionUninstalled struct {
+	Notification `json:"-"`
+}
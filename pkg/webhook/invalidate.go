@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+// ProductInvalidator is implemented by caches of catalog reads, such as
+// squarespace.CachedClient.
+type ProductInvalidator interface {
+	InvalidateProduct(ctx context.Context, productID string) error
+	InvalidateAll(ctx context.Context) error
+}
+
+// InvalidateCaches registers handlers that drop cached products whose stock
+// an order may have changed, and every cached read when the extension is
+// uninstalled. With no caches there is nothing to invalidate, so it registers
+// nothing rather than fetch orders for no reason.
+func InvalidateCaches(r *Receiver, api *squarespace.Client, caches ...ProductInvalidator) {
+	if len(caches) == 0 {
+		return
+	}
+
+	invalidateOrder := func(ctx context.Context, orderID string) error {
+		order, err := api.GetOrder(ctx, orderID)
+		if errors.Is(err, squarespace.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var errs []error
+		for _, item := range order.LineItems {
+			for _, c := range caches {
+				errs = append(errs, c.InvalidateProduct(ctx, item.ProductID))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	r.OnOrderCreate(func(ctx context.Context, event OrderCreated) error {
+		return invalidateOrder(ctx, event.OrderID)
+	})
+	r.OnOrderUpdate(func(ctx context.Context, event OrderUpdated) error {
+		return invalidateOrder(ctx, event.OrderID)
+	})
+	r.OnExtensionUninstall(func(ctx context.Context, _ ExtensionUninstalled) error {
+		var errs []error
+		for _, c := range caches {
+			errs = append(errs, c.InvalidateAll(ctx))
+		}
+		return errors.Join(errs...)
+	})
+}
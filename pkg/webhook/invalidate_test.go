@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+type recordingCache struct {
+	products []string
+	all      int
+}
+
+func (c *recordingCache) InvalidateProduct(_ context.Context, productID string) error {
+	c.products = append(c.products, productID)
+	return nil
+}
+
+func (c *recordingCache) InvalidateAll(context.Context) error {
+	c.all++
+	return nil
+}
+
+func TestInvalidateCaches(t *testing.T) {
+	server := sstest.NewServer()
+	defer server.Close()
+	server.SeedDemoData()
+	api := squarespace.NewClient(server.Config())
+	ctx := context.Background()
+
+	order := Notification{ID: "n-1", Topic: TopicOrderUpdate, Data: []byte(`{"orderId":"order-1000"}`)}
+
+	t.Run("no caches", func(t *testing.T) {
+		receiver := NewReceiver(nil, nil, 0)
+		InvalidateCaches(receiver, api)
+
+		before := server.Requests()
+		for _, h := range receiver.handlers[TopicOrderUpdate] {
+			h(ctx, order)
+		}
+		if server.Requests() != before {
+			t.Errorf("made %d upstream requests with no cache to invalidate", server.Requests()-before)
+		}
+	})
+
+	t.Run("order update", func(t *testing.T) {
+		receiver := NewReceiver(nil, nil, 0)
+		cache := &recordingCache{}
+		InvalidateCaches(receiver, api, cache)
+
+		for _, h := range receiver.handlers[TopicOrderUpdate] {
+			if err := h(ctx, order); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(cache.products) != 1 || cache.products[0] != "prod-print" {
+			t.Errorf("invalidated %v, want [prod-print]", cache.products)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body.
+const SignatureHeader = "Squarespace-Signature"
+
+var (
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrMalformed        = errors.New("webhook: malformed notification")
+	ErrStale            = errors.New("webhook: notification is too old")
+	// ErrDuplicate is returned for a notification ID that has already been
+	// accepted. Callers should acknowledge it without processing it again.
+	ErrDuplicate = errors.New("webhook: duplicate notification")
+)
+
+// Handler processes one verified notification. Returning an error makes the
+// receiver forget the notification so a redelivery is processed again.
+type Handler func(ctx context.Context, n Notification) error
+
+// Receiver verifies and dispatches notifications. Each subscription has its
+// own secret, so a signature is accepted if it matches any of them.
+type Receiver struct {
+	seen   ReplayStore
+	maxAge time.Duration
+
+	mu       sync.RWMutex
+	secrets  [][]byte
+	handlers map[Topic][]Handler
+}
+
+// NewReceiver rejects notifications created more than maxAge ago and
+// remembers accepted IDs in seen for at least as long.
+func NewReceiver(secrets []string, seen ReplayStore, maxAge time.Duration) *Receiver {
+	r := &Receiver{
+		seen:     seen,
+		maxAge:   maxAge,
+		handlers: make(map[Topic][]Handler),
+	}
+	r.SetSecrets(secrets)
+	return r
+}
+
+// SetSecrets replaces the subscription secrets, e.g. after a rotation.
+// Squarespace issues secrets hex-encoded; other values are used as-is.
+func (r *Receiver) SetSecrets(secrets []string) {
+	keys := make([][]byte, 0, len(secrets))
+	for _, secret := range secrets {
+		key, err := hex.DecodeString(secret)
+		if err != nil {
+			key = []byte(secret)
+		}
+		keys = append(keys, key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets = keys
+}
+
+// Handle registers h for topic. Handlers run in registration order.
+func (r *Receiver) Handle(topic Topic, h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[topic] = append(r.handlers[topic], h)
+}
+
+// OnOrderCreate registers a typed handler for order.create.
+func (r *Receiver) OnOrderCreate(h func(ctx context.Context, event OrderCreated) error) {
+	r.Handle(TopicOrderCreate, func(ctx context.Context, n Notification) error {
+		event := OrderCreated{Notification: n}
+		if err := decodeData(n, &event); err != nil {
+			return err
+		}
+		return h(ctx, event)
+	})
+}
+
+// OnOrderUpdate registers a typed handler for order.update.
+func (r *Receiver) OnOrderUpdate(h func(ctx context.Context, event OrderUpdated) error) {
+	r.Handle(TopicOrderUpdate, func(ctx context.Context, n Notification) error {
+		event := OrderUpdated{Notification: n}
+		if err := decodeData(n, &event); err != nil {
+			return err
+		}
+		return h(ctx, event)
+	})
+}
+
+// OnExtensionUninstall registers a typed handler for extension.uninstall.
+func (r *Receiver) OnExtensionUninstall(h func(ctx context.Context, event ExtensionUninstalled) error) {
+	r.Handle(TopicExtensionUninstall, func(ctx context.Context, n Notification) error {
+		return h(ctx, ExtensionUninstalled{Notification: n})
+	})
+}
+
+// Verify reports whether signature is a valid signature of body under any
+// subscription secret.
+func (r *Receiver) Verify(body []byte, signature string) bool {
+	sum, err := hex.DecodeString(signature)
+	if err != nil || len(sum) == 0 {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, key := range r.secrets {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		if hmac.Equal(mac.Sum(nil), sum) {
+			return true
+		}
+	}
+	return false
+}
+
+// Receive verifies a delivery, rejects stale and repeated notifications and
+// runs the handlers for its topic. Topics without handlers are accepted and
+// ignored.
+func (r *Receiver) Receive(ctx context.Context, body []byte, signature string) (*Notification, error) {
+	if !r.Verify(body, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var n Notification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if n.ID == "" || n.Topic == "" {
+		return nil, fmt.Errorf("%w: id and topic are required", ErrMalformed)
+	}
+	if r.maxAge > 0 && time.Since(n.CreatedOn) > r.maxAge {
+		return &n, ErrStale
+	}
+
+	claimed, err := r.seen.Claim(ctx, n.ID, r.retention())
+	if err != nil {
+		return &n, err
+	}
+	if !claimed {
+		return &n, ErrDuplicate
+	}
+
+	r.mu.RLock()
+	handlers := r.handlers[n.Topic]
+	r.mu.RUnlock()
+	if len(handlers) == 0 {
+		log.Printf("webhook: no handler for topic %s, ignoring notification %s", n.Topic, n.ID)
+	}
+
+	for _, h := range handlers {
+		if err := h(ctx, n); err != nil {
+			if forgetErr := r.seen.Forget(ctx, n.ID); forgetErr != nil {
+				log.Printf("webhook: failed to forget notification %s: %v", n.ID, forgetErr)
+			}
+			return &n, fmt.Errorf("webhook: handle %s %s: %w", n.Topic, n.ID, err)
+		}
+	}
+	return &n, nil
+}
+
+// retention keeps IDs past maxAge, after which the age check rejects replays.
+func (r *Receiver) retention() time.Duration {
+	if r.maxAge > 0 {
+		return r.maxAge + time.Minute
+	}
+	return 24 * time.Hour
+}
+
+func decodeData(n Notification, v interface{}) error {
+	if len(n.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(n.Data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return nil
+}
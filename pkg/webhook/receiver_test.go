@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+const testSecret = "5ec7e7"
+
+// sign returns the hex HMAC-SHA256 of body as Squarespace signs deliveries,
+// decoding secret the way SetSecrets does.
+func sign(secret string, body []byte) string {
+	key, err := hex.DecodeString(secret)
+	if err != nil {
+		key = []byte(secret)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func notificationBody(id string, createdOn time.Time) []byte {
+	return []byte(fmt.Sprintf(`{"id":%q,"topic":"order.create","createdOn":%q,"data":{"orderId":"order-1000"}}`,
+		id, createdOn.Format(time.RFC3339Nano)))
+}
+
+func TestReceiveVerifiesDeliveries(t *testing.T) {
+	fresh := notificationBody("n-1", time.Now())
+	stale := notificationBody("n-2", time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		wantErr   error
+	}{
+		{"good signature", fresh, sign(testSecret, fresh), nil},
+		{"rotated secret", fresh, sign("01d5ec", fresh), nil},
+		{"wrong secret", fresh, sign("ba5e", fresh), ErrInvalidSignature},
+		{"malformed hex", fresh, "not-hex", ErrInvalidSignature},
+		{"empty signature", fresh, "", ErrInvalidSignature},
+		{"truncated signature", fresh, sign(testSecret, fresh)[:32], ErrInvalidSignature},
+		{"tampered body", []byte(`{"id":"n-1","topic":"order.create"}`), sign(testSecret, fresh), ErrInvalidSignature},
+		{"stale", stale, sign(testSecret, stale), ErrStale},
+		{"not JSON", []byte("nope"), sign(testSecret, []byte("nope")), ErrMalformed},
+		{"missing topic", []byte(`{"id":"n-3"}`), sign(testSecret, []byte(`{"id":"n-3"}`)), ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := NewReceiver([]string{testSecret, "01d5ec"}, NewMemoryReplayStore(), 5*time.Minute)
+			var handled []string
+			receiver.OnOrderCreate(func(_ context.Context, event OrderCreated) error {
+				handled = append(handled, event.OrderID)
+				return nil
+			})
+
+			_, err := receiver.Receive(context.Background(), tt.body, tt.signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Receive() error = %v, want %v", err, tt.wantErr)
+			}
+			wantHandled := 0
+			if tt.wantErr == nil {
+				wantHandled = 1
+			}
+			if len(handled) != wantHandled {
+				t.Errorf("handled %v, want %d order(s)", handled, wantHandled)
+			}
+		})
+	}
+}
+
+func TestReceiveRejectsDuplicatesUntilHandlerFails(t *testing.T) {
+	receiver := NewReceiver([]string{testSecret}, NewMemoryReplayStore(), 5*time.Minute)
+	ctx := context.Background()
+
+	calls := 0
+	errHandler := errors.New("database down")
+	receiver.OnOrderCreate(func(context.Context, OrderCreated) error {
+		calls++
+		if calls == 1 {
+			return errHandler
+		}
+		return nil
+	})
+
+	body := notificationBody("n-1", time.Now())
+	signature := sign(testSecret, body)
+
+	// A failed handler forgets the ID, so the redelivery is processed
+	if _, err := receiver.Receive(ctx, body, signature); !errors.Is(err, errHandler) {
+		t.Fatalf("first Receive() error = %v, want the handler's", err)
+	}
+	if _, err := receiver.Receive(ctx, body, signature); err != nil {
+		t.Fatalf("retried Receive() error = %v", err)
+	}
+	if _, err := receiver.Receive(ctx, body, signature); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("repeated Receive() error = %v, want ErrDuplicate", err)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+
+	// Topics without handlers are still claimed
+	unhandled := []byte(fmt.Sprintf(`{"id":"n-2","topic":"order.update","createdOn":%q}`, time.Now().Format(time.RFC3339)))
+	if _, err := receiver.Receive(ctx, unhandled, sign(testSecret, unhandled)); err != nil {
+		t.Fatalf("unhandled topic Receive() error = %v", err)
+	}
+	if _, err := receiver.Receive(ctx, unhandled, sign(testSecret, unhandled)); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("repeated unhandled Receive() error = %v, want ErrDuplicate", err)
+	}
+}
+
+func TestSetSecretsRotates(t *testing.T) {
+	body := notificationBody("n-1", time.Now())
+	receiver := NewReceiver([]string{testSecret}, NewMemoryReplayStore(), 0)
+	if !receiver.Verify(body, sign(testSecret, body)) {
+		t.Fatal("Verify() = false under the current secret")
+	}
+
+	receiver.SetSecrets([]string{"01d5ec", "plain-text-secret"})
+	if receiver.Verify(body, sign(testSecret, body)) {
+		t.Error("Verify() = true under a rotated-out secret")
+	}
+	if !receiver.Verify(body, sign("plain-text-secret", body)) {
+		t.Error("Verify() = false under a secret that isn't hex")
+	}
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/models"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+)
+
+// Reconcile makes sure exactly one Squarespace subscription delivers topics
+// to endpointURL: it creates one if none exists, updates the topics of the
+// first match and deletes other subscriptions pointing at endpointURL.
+//
+// Squarespace only reveals a secret when a subscription is created or its
+// secret rotated. The returned subscription carries its secret when one of
+// those happened; with rotateSecret set, an existing subscription's secret
+// is rotated so the caller learns it. Rotating invalidates the secret every
+// other instance verifies with, so it should only happen when asked for.
+func Reconcile(ctx context.Context, api squarespace.API, endpointURL string, topics []string, rotateSecret bool) (*models.WebhookSubscription, error) {
+	want := append([]string(nil), topics...)
+	sort.Strings(want)
+
+	existing, err := api.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscription *models.WebhookSubscription
+	for i := range existing {
+		if existing[i].EndpointURL != endpointURL {
+			continue
+		}
+		if subscription == nil {
+			subscription = &existing[i]
+			continue
+		}
+		if err := api.DeleteWebhookSubscription(ctx, existing[i].ID); err != nil {
+			return nil, err
+		}
+		log.Printf("webhook: deleted duplicate subscription %s", existing[i].ID)
+	}
+
+	if subscription == nil {
+		created, err := api.CreateWebhookSubscription(ctx, endpointURL, want)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("webhook: created subscription %s for %v", created.ID, want)
+		return created, nil
+	}
+
+	if !sameTopics(subscription.Topics, want) {
+		updated, err := api.UpdateWebhookSubscription(ctx, subscription.ID, "", want)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("webhook: updated subscription %s topics to %v", updated.ID, want)
+		subscription = updated
+	}
+
+	if rotateSecret {
+		secret, err := api.RotateSubscriptionSecret(ctx, subscription.ID)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("webhook: rotated secret of subscription %s", subscription.ID)
+		subscription.Secret = secret
+	}
+	return subscription, nil
+}
+
+// sameTopics compares got against the sorted topics in want.
+func sameTopics(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	sorted := append([]string(nil), got...)
+	sort.Strings(sorted)
+	for i := range sorted {
+		if sorted[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
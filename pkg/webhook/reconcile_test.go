@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace"
+	"github.com/birddigital/store.adrienbird.net/pkg/squarespace/sstest"
+)
+
+func TestReconcileRotatesOnlyWhenAsked(t *testing.T) {
+	server := sstest.NewServer()
+	defer server.Close()
+	api := squarespace.NewClient(server.Config())
+	ctx := context.Background()
+
+	const endpoint = "https://store.example.com/webhooks/squarespace"
+	created, err := Reconcile(ctx, api, endpoint, []string{"order.update", "order.create"}, false)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if created.Secret == "" {
+		t.Fatal("Reconcile() of a new subscription returned no secret")
+	}
+
+	kept, err := Reconcile(ctx, api, endpoint, []string{"order.create"}, false)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if kept.ID != created.ID || kept.Secret != "" {
+		t.Errorf("Reconcile() = %s with secret %q, want %s without one", kept.ID, kept.Secret, created.ID)
+	}
+	subscriptions := server.Subscriptions()
+	if len(subscriptions) != 1 || subscriptions[0].Secret != created.Secret {
+		t.Fatalf("Reconcile() without rotation changed the secret")
+	}
+	if len(subscriptions[0].Topics) != 1 {
+		t.Errorf("topics = %v, want [order.create]", subscriptions[0].Topics)
+	}
+
+	rotated, err := Reconcile(ctx, api, endpoint, []string{"order.create"}, true)
+	if err != nil {
+		t.Fatalf("Reconcile(rotate) error = %v", err)
+	}
+	if rotated.Secret == "" || rotated.Secret == created.Secret {
+		t.Errorf("Reconcile(rotate) secret = %q, want a new one", rotated.Secret)
+	}
+	if got := server.Subscriptions()[0].Secret; got != rotated.Secret {
+		t.Errorf("fake holds secret %q, want the rotated %q", got, rotated.Secret)
+	}
+}
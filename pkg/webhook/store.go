@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ReplayStore remembers notification IDs that have been accepted.
+type ReplayStore interface {
+	// Claim records id for ttl, returning false if it is already recorded.
+	Claim(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// Forget drops id so a redelivery is accepted.
+	Forget(ctx context.Context, id string) error
+}
+
+// MemoryReplayStore keeps notification IDs in process memory.
+type MemoryReplayStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{expires: make(map[string]time.Time)}
+}
+
+func (s *MemoryReplayStore) Claim(_ context.Context, id string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := s.expires[id]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+	s.expires[id] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryReplayStore) Forget(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.expires, id)
+	return nil
+}
+
+// Sweep removes expired IDs.
+func (s *MemoryReplayStore) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, expiresAt := range s.expires {
+		if !now.Before(expiresAt) {
+			delete(s.expires, id)
+		}
+	}
+}